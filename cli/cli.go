@@ -19,6 +19,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +57,10 @@ const (
 	DefaultMigrationsDirectory = "migrations"
 	DefaultMetadataDirectory   = "metadata"
 	DefaultSeedsDirectory      = "seeds"
+
+	// DefaultMigrationsChunkSize sends the migrations in a single bulk query,
+	// which is applied all or nothing, unless chunks are configured
+	DefaultMigrationsChunkSize = "0"
 )
 
 const (
@@ -71,6 +76,27 @@ visit https://hasura.io/docs/1.0/graphql/manual/guides/telemetry.html
 `
 )
 
+// Modes in which migrations can be applied on the server
+const (
+	// MigrationsApplyModeBulk sends all migrations in a single bulk query
+	MigrationsApplyModeBulk = "bulk"
+	// MigrationsApplyModePerMigration sends and records each migration separately
+	MigrationsApplyModePerMigration = "per-migration"
+)
+
+// Layouts of the tables in the metadata directory
+const (
+	// MetadataTablesLayoutSingleFile writes all the tables to tables.yaml
+	MetadataTablesLayoutSingleFile = "single-file"
+	// MetadataTablesLayoutPerTable writes each table to its own file in the
+	// tables directory, which are included by tables.yaml
+	MetadataTablesLayoutPerTable = "per-table"
+)
+
+// sqlIdentifierRegex matches the names allowed for the schema and the tables
+// holding the migrations state
+var sqlIdentifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
 // ConfigVersion defines the version of the Config.
 type ConfigVersion int
 
@@ -292,10 +318,40 @@ type Config struct {
 
 	// MetadataDirectory defines the directory where the metadata files were stored.
 	MetadataDirectory string `yaml:"metadata_directory,omitempty"`
+	// MetadataTablesLayout defines whether the tables are stored in a single
+	// file (single-file) or in a file per table (per-table)
+	MetadataTablesLayout string `yaml:"metadata_tables_layout,omitempty"`
 	// MigrationsDirectory defines the directory where the migration files were stored.
 	MigrationsDirectory string `yaml:"migrations_directory,omitempty"`
 	// SeedsDirectory defines the directory where seed files will be stored
 	SeedsDirectory string `yaml:"seeds_directory,omitempty"`
+	// MigrationsLockTimeout defines how long to wait for the migrations lock
+	// held by another process on the server, e.g. "30s"
+	MigrationsLockTimeout string `yaml:"migrations_lock_timeout,omitempty"`
+	// MigrationsApplyMode defines whether migrations are applied in a single
+	// bulk query (bulk) or one by one (per-migration)
+	MigrationsApplyMode string `yaml:"migrations_apply_mode,omitempty"`
+	// MigrationsChunkSize splits the migrations into multiple bulk queries
+	// of at most this size, e.g. "1MB". "0" sends all of them in one query.
+	MigrationsChunkSize string `yaml:"migrations_chunk_size,omitempty"`
+	// MigrationsChunkCount splits the migrations into multiple bulk queries
+	// of at most this number of migrations, 0 means no limit
+	MigrationsChunkCount int `yaml:"migrations_chunk_count,omitempty"`
+	// MigrationsSchema defines the schema of the tables holding the migrations
+	// state on the database, defaults to hdb_catalog
+	MigrationsSchema string `yaml:"schema,omitempty"`
+	// MigrationsTable defines the table holding the applied migrations,
+	// defaults to schema_migrations
+	MigrationsTable string `yaml:"migrations_table,omitempty"`
+	// SettingsTable defines the table holding the migrations settings,
+	// defaults to migration_settings
+	SettingsTable string `yaml:"settings_table,omitempty"`
+	// MigrationsActor is recorded in the migrations history as the one
+	// applying the migrations, defaults to the OS user
+	MigrationsActor string `yaml:"migrations_actor,omitempty"`
+	// MigrationsTemplating renders sql migrations as templates, giving
+	// access to environment variables, e.g. {{ env "APP_ROLE" }}
+	MigrationsTemplating bool `yaml:"migrations_templating,omitempty"`
 	// ActionConfig defines the config required to create or generate codegen for an action.
 	ActionConfig *types.ActionExecutionConfig `yaml:"actions,omitempty"`
 }
@@ -328,6 +384,9 @@ type ExecutionContext struct {
 	MigrationDir string
 	// MetadataDir is the name of directory where metadata files are stored.
 	MetadataDir string
+	// MetadataEnvironment is the environment whose overlays in MetadataDir
+	// are applied to the metadata, none when empty.
+	MetadataEnvironment string
 	// Seed directory -- directory in which seed files are to be stored
 	SeedsDirectory string
 	// ConfigFile is the file where endpoint etc. are stored.
@@ -526,8 +585,45 @@ func (ec *ExecutionContext) Validate() error {
 		return errors.Wrap(err, "ensuring codegen-assets repo failed")
 	}
 
+	err = ec.ValidateProject()
+	if err != nil {
+		return err
+	}
+
+	ec.Logger.Debug("graphql engine endpoint: ", ec.Config.ServerConfig.Endpoint)
+	ec.Logger.Debug("graphql engine admin_secret: ", ec.Config.ServerConfig.AdminSecret)
+
+	// get version from the server and match with the cli version
+	err = ec.checkServerVersion()
+	if err != nil {
+		return errors.Wrap(err, "version check")
+	}
+
+	// get the server feature flags
+	err = ec.Version.GetServerFeatureFlags()
+	if err != nil {
+		return errors.Wrap(err, "error in getting server feature flags")
+	}
+
+	state := util.GetServerState(ec.Config.ServerConfig.GetQueryEndpoint(), ec.Config.ServerConfig.AdminSecret, ec.Config.ServerConfig.TLSConfig, ec.Version.ServerSemver, ec.Logger)
+	ec.ServerUUID = state.UUID
+	ec.Telemetry.ServerUUID = ec.ServerUUID
+	ec.Logger.Debugf("server: uuid: %s", ec.ServerUUID)
+	// Set headers required for communicating with HGE
+	if ec.Config.AdminSecret != "" {
+		headers := map[string]string{
+			GetAdminSecretHeaderName(ec.Version): ec.Config.AdminSecret,
+		}
+		ec.SetHGEHeaders(headers)
+	}
+	return nil
+}
+
+// ValidateProject validates the ExecutionDirectory and reads the config of
+// the project, without contacting the server.
+func (ec *ExecutionContext) ValidateProject() error {
 	// validate execution directory
-	err = ec.validateDirectory()
+	err := ec.validateDirectory()
 	if err != nil {
 		return errors.Wrap(err, "validating current directory failed")
 	}
@@ -574,33 +670,6 @@ func (ec *ExecutionContext) Validate() error {
 			}
 		}
 	}
-
-	ec.Logger.Debug("graphql engine endpoint: ", ec.Config.ServerConfig.Endpoint)
-	ec.Logger.Debug("graphql engine admin_secret: ", ec.Config.ServerConfig.AdminSecret)
-
-	// get version from the server and match with the cli version
-	err = ec.checkServerVersion()
-	if err != nil {
-		return errors.Wrap(err, "version check")
-	}
-
-	// get the server feature flags
-	err = ec.Version.GetServerFeatureFlags()
-	if err != nil {
-		return errors.Wrap(err, "error in getting server feature flags")
-	}
-
-	state := util.GetServerState(ec.Config.ServerConfig.GetQueryEndpoint(), ec.Config.ServerConfig.AdminSecret, ec.Config.ServerConfig.TLSConfig, ec.Version.ServerSemver, ec.Logger)
-	ec.ServerUUID = state.UUID
-	ec.Telemetry.ServerUUID = ec.ServerUUID
-	ec.Logger.Debugf("server: uuid: %s", ec.ServerUUID)
-	// Set headers required for communicating with HGE
-	if ec.Config.AdminSecret != "" {
-		headers := map[string]string{
-			GetAdminSecretHeaderName(ec.Version): ec.Config.AdminSecret,
-		}
-		ec.SetHGEHeaders(headers)
-	}
 	return nil
 }
 
@@ -654,8 +723,18 @@ func (ec *ExecutionContext) readConfig() error {
 	v.SetDefault("api_paths.pg_dump", "v1alpha1/pg_dump")
 	v.SetDefault("api_paths.version", "v1/version")
 	v.SetDefault("metadata_directory", "")
+	v.SetDefault("metadata_tables_layout", MetadataTablesLayoutSingleFile)
 	v.SetDefault("migrations_directory", DefaultMigrationsDirectory)
 	v.SetDefault("seeds_directory", DefaultSeedsDirectory)
+	v.SetDefault("migrations_lock_timeout", "")
+	v.SetDefault("migrations_apply_mode", MigrationsApplyModeBulk)
+	v.SetDefault("migrations_chunk_size", DefaultMigrationsChunkSize)
+	v.SetDefault("migrations_chunk_count", 0)
+	v.SetDefault("migrations_actor", "")
+	v.SetDefault("migrations_templating", false)
+	v.SetDefault("schema", "")
+	v.SetDefault("migrations_table", "")
+	v.SetDefault("settings_table", "")
 	v.SetDefault("actions.kind", "synchronous")
 	v.SetDefault("actions.handler_webhook_baseurl", "http://localhost:3000")
 	v.SetDefault("actions.codegen.framework", "")
@@ -686,9 +765,19 @@ func (ec *ExecutionContext) readConfig() error {
 			InsecureSkipTLSVerify: v.GetBool("insecure_skip_tls_verify"),
 			CAPath:                v.GetString("certificate_authority"),
 		},
-		MetadataDirectory:   v.GetString("metadata_directory"),
-		MigrationsDirectory: v.GetString("migrations_directory"),
-		SeedsDirectory:      v.GetString("seeds_directory"),
+		MetadataDirectory:     v.GetString("metadata_directory"),
+		MetadataTablesLayout:  v.GetString("metadata_tables_layout"),
+		MigrationsDirectory:   v.GetString("migrations_directory"),
+		SeedsDirectory:        v.GetString("seeds_directory"),
+		MigrationsLockTimeout: v.GetString("migrations_lock_timeout"),
+		MigrationsApplyMode:   v.GetString("migrations_apply_mode"),
+		MigrationsChunkSize:   v.GetString("migrations_chunk_size"),
+		MigrationsChunkCount:  v.GetInt("migrations_chunk_count"),
+		MigrationsActor:       v.GetString("migrations_actor"),
+		MigrationsTemplating:  v.GetBool("migrations_templating"),
+		MigrationsSchema:      v.GetString("schema"),
+		MigrationsTable:       v.GetString("migrations_table"),
+		SettingsTable:         v.GetString("settings_table"),
 		ActionConfig: &types.ActionExecutionConfig{
 			Kind:                  v.GetString("actions.kind"),
 			HandlerWebhookBaseURL: v.GetString("actions.handler_webhook_baseurl"),
@@ -702,6 +791,36 @@ func (ec *ExecutionContext) readConfig() error {
 	if !ec.Config.Version.IsValid() {
 		return ErrInvalidConfigVersion
 	}
+	if ec.Config.MigrationsLockTimeout != "" {
+		if _, err := time.ParseDuration(ec.Config.MigrationsLockTimeout); err != nil {
+			return errors.Wrap(err, "invalid migrations_lock_timeout")
+		}
+	}
+	switch ec.Config.MigrationsApplyMode {
+	case MigrationsApplyModeBulk, MigrationsApplyModePerMigration:
+	default:
+		return fmt.Errorf("invalid migrations_apply_mode %q, should be one of %s or %s", ec.Config.MigrationsApplyMode, MigrationsApplyModeBulk, MigrationsApplyModePerMigration)
+	}
+	switch ec.Config.MetadataTablesLayout {
+	case MetadataTablesLayoutSingleFile, MetadataTablesLayoutPerTable:
+	default:
+		return fmt.Errorf("invalid metadata_tables_layout %q, should be one of %s or %s", ec.Config.MetadataTablesLayout, MetadataTablesLayoutSingleFile, MetadataTablesLayoutPerTable)
+	}
+	if _, err := util.ParseByteSize(ec.Config.MigrationsChunkSize); err != nil {
+		return errors.Wrap(err, "invalid migrations_chunk_size")
+	}
+	if ec.Config.MigrationsChunkCount < 0 {
+		return fmt.Errorf("invalid migrations_chunk_count %d, should not be negative", ec.Config.MigrationsChunkCount)
+	}
+	for key, value := range map[string]string{
+		"schema":           ec.Config.MigrationsSchema,
+		"migrations_table": ec.Config.MigrationsTable,
+		"settings_table":   ec.Config.SettingsTable,
+	} {
+		if value != "" && !sqlIdentifierRegex.MatchString(value) {
+			return fmt.Errorf("invalid %s %q, should only contain lowercase letters, digits and underscores", key, value)
+		}
+	}
 	err = ec.Config.ServerConfig.ParseEndpoint()
 	if err != nil {
 		return errors.Wrap(err, "unable to parse server endpoint")
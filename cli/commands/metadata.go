@@ -34,6 +34,7 @@ func NewMetadataCmd(ec *cli.ExecutionContext) *cobra.Command {
 		newMetadataReloadCmd(ec),
 		newMetadataApplyCmd(ec),
 		newMetadataInconsistencyCmd(ec),
+		newMetadataValidateCmd(ec),
 	)
 
 	f := metadataCmd.PersistentFlags()
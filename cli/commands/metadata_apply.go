@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/hasura/graphql-engine/cli/migrate"
@@ -25,9 +26,25 @@ func newMetadataApplyCmd(ec *cli.ExecutionContext) *cobra.Command {
   # Use with admin secret:
   hasura metadata apply --admin-secret "<admin-secret>"
 
+  # Apply only the changes from the server metadata, without clearing it first:
+  hasura metadata apply --incremental
+
+  # Apply metadata with the values of the staging environment from metadata/overlays/staging:
+  hasura metadata apply --env staging
+
   # Apply metadata to an instance specified by the flag:
   hasura metadata apply --endpoint "<endpoint>"`,
 		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Environment == "" {
+				return nil
+			}
+			if ec.Config.Version != cli.V2 || ec.MetadataDir == "" || opts.FromFile {
+				return fmt.Errorf("--env can be used only with a metadata directory")
+			}
+			ec.MetadataEnvironment = opts.Environment
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if opts.dryRun {
 				o := &MetadataDiffOptions{
@@ -52,6 +69,8 @@ func newMetadataApplyCmd(ec *cli.ExecutionContext) *cobra.Command {
 
 	f.BoolVar(&opts.FromFile, "from-file", false, "apply metadata from migrations/metadata.[yaml|json]")
 	f.BoolVar(&opts.dryRun, "dry-run", false, "show a diff instead of applying the metadata")
+	f.BoolVar(&opts.Incremental, "incremental", false, "apply only the changes from the server metadata, replacing the metadata when they cannot be applied one by one")
+	f.StringVar(&opts.Environment, "env", "", "apply the overlays of the environment in metadata/overlays/<env> to the metadata")
 
 	return metadataApplyCmd
 }
@@ -63,6 +82,11 @@ type MetadataApplyOptions struct {
 
 	FromFile bool
 	dryRun   bool
+	// Incremental sends the metadata queries for the changes from the server
+	// metadata instead of replacing it
+	Incremental bool
+	// Environment whose overlays are applied to the metadata
+	Environment string
 }
 
 func (o *MetadataApplyOptions) Run() error {
@@ -78,5 +102,6 @@ func (o *MetadataApplyOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	migrateDrv.EnableIncrementalMetadata(o.Incremental)
 	return executeMetadata(o.ActionType, migrateDrv, o.EC)
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"github.com/aryann/difflib"
 	"github.com/hasura/graphql-engine/cli"
 	"github.com/hasura/graphql-engine/cli/metadata"
+	metadatadiff "github.com/hasura/graphql-engine/cli/metadata/diff"
 	"github.com/mgutz/ansi"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -23,6 +25,9 @@ type MetadataDiffOptions struct {
 	Output io.Writer
 	Args   []string
 
+	// output format of the changes, text or json
+	output string
+
 	// two Metadata to diff, 2nd is server if it's empty
 	Metadata [2]string
 }
@@ -35,9 +40,13 @@ func newMetadataDiffCmd(ec *cli.ExecutionContext) *cobra.Command {
 
 	metadataDiffCmd := &cobra.Command{
 		Use:   "diff [file1] [file2]",
-		Short: "(PREVIEW) Show a highlighted diff of Hasura metadata",
+		Short: "(PREVIEW) Show the changes between two sets of Hasura metadata",
 		Long: `(PREVIEW) Show changes between two different sets of Hasura metadata.
-By default, shows changes between exported metadata file and server metadata.`,
+By default, shows changes between exported metadata file and server metadata.
+
+Changes are shown per metadata object. Tables and functions are matched by
+schema and name, permissions by role, and relationships, event triggers, remote
+schemas, actions and cron triggers by name, so reordering them is not a change.`,
 		Example: `  # NOTE: This command is in preview, usage and diff format may change.
 
   # Show changes between server metadata and the exported metadata file:
@@ -49,21 +58,36 @@ By default, shows changes between exported metadata file and server metadata.`,
   # Show changes between metadata from metadata.yaml and metadata_old.yaml:
   hasura metadata diff metadata.yaml metadata_old.yaml
 
+  # Show the changes as json:
+  hasura metadata diff -o json
+
   # Apply admin secret for Hasura GraphQL Engine:
   hasura metadata diff --admin-secret "<admin-secret>"
 
   # Diff metadata on a different Hasura instance:
   hasura metadata diff --endpoint "<endpoint>"`,
 		Args: cobra.MaximumNArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputFormatText, outputFormatJSON:
+				return nil
+			}
+			return fmt.Errorf("invalid output format %q, must be one of %s or %s", opts.output, outputFormatText, outputFormatJSON)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Args = args
 			return opts.Run()
 		},
 	}
 
+	f := metadataDiffCmd.Flags()
+	f.StringVarP(&opts.output, "output", "o", outputFormatText, "output format for the changes (text, json)")
+
 	return metadataDiffCmd
 }
 
+const outputFormatText = "text"
+
 func (o *MetadataDiffOptions) runv2(args []string) error {
 	messageFormat := "Showing diff between %s and %s..."
 	message := ""
@@ -94,7 +118,6 @@ func (o *MetadataDiffOptions) runv2(args []string) error {
 		message = fmt.Sprintf(messageFormat, o.Metadata[0], o.Metadata[1])
 	}
 	o.EC.Logger.Info(message)
-	var oldYaml, newYaml []byte
 	migrateDrv, err := migrate.NewMigrate(o.EC, true)
 	if err != nil {
 		return err
@@ -123,10 +146,6 @@ func (o *MetadataDiffOptions) runv2(args []string) error {
 	if err != nil {
 		return err
 	}
-	newYaml, err = yaml.Marshal(serverMeta)
-	if err != nil {
-		return errors.Wrap(err, "cannot unmarshall server metadata")
-	}
 
 	// build local metadata
 	migrate.SetMetadataPluginsWithDir(o.EC, migrateDrv, o.Metadata[0])
@@ -134,13 +153,8 @@ func (o *MetadataDiffOptions) runv2(args []string) error {
 	if err != nil {
 		return err
 	}
-	oldYaml, err = yaml.Marshal(localMeta)
-	if err != nil {
-		return errors.Wrap(err, "cannot unmarshal local metadata")
-	}
 
-	printDiff(string(oldYaml), string(newYaml), o.Output)
-	return nil
+	return o.printChanges(localMeta, serverMeta)
 }
 
 func (o *MetadataDiffOptions) runv1(args []string) error {
@@ -198,8 +212,14 @@ func (o *MetadataDiffOptions) runv1(args []string) error {
 		return errors.Wrap(err, "cannot read file")
 	}
 
-	printDiff(string(oldYaml), string(newYaml), o.Output)
-	return nil
+	var oldMeta, newMeta yaml.MapSlice
+	if err := yaml.Unmarshal(oldYaml, &oldMeta); err != nil {
+		return errors.Wrapf(err, "cannot parse %s", o.Metadata[0])
+	}
+	if err := yaml.Unmarshal(newYaml, &newMeta); err != nil {
+		return errors.Wrap(err, "cannot parse server metadata")
+	}
+	return o.printChanges(oldMeta, newMeta)
 }
 
 func (o *MetadataDiffOptions) Run() error {
@@ -209,6 +229,71 @@ func (o *MetadataDiffOptions) Run() error {
 	return o.runv1(o.Args)
 }
 
+// printChanges prints the changes of the metadata objects from before to after
+func (o *MetadataDiffOptions) printChanges(before, after interface{}) error {
+	changes, err := metadatadiff.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if o.output == outputFormatJSON {
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "cannot marshal changes")
+		}
+		_, err = o.Output.Write(append(data, '\n'))
+		return err
+	}
+	if len(changes) == 0 {
+		o.EC.Logger.Info("no changes")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintln(o.Output, ansi.Color(changeSign(change.Type)+" "+change.String(), changeColor(change.Type)))
+		for _, field := range change.Fields {
+			var text string
+			switch field.Type() {
+			case metadatadiff.Added:
+				text = fmt.Sprintf("%s: %s", field.Path, diffValue(field.After))
+			case metadatadiff.Removed:
+				text = fmt.Sprintf("%s: %s", field.Path, diffValue(field.Before))
+			default:
+				text = fmt.Sprintf("%s: %s -> %s", field.Path, diffValue(field.Before), diffValue(field.After))
+			}
+			fmt.Fprintf(o.Output, "    %s\n", ansi.Color(changeSign(field.Type())+" "+text, changeColor(field.Type())))
+		}
+	}
+	return nil
+}
+
+func changeSign(t metadatadiff.ChangeType) string {
+	switch t {
+	case metadatadiff.Added:
+		return "+"
+	case metadatadiff.Removed:
+		return "-"
+	}
+	return "~"
+}
+
+func changeColor(t metadatadiff.ChangeType) string {
+	switch t {
+	case metadatadiff.Added:
+		return "green"
+	case metadatadiff.Removed:
+		return "red"
+	}
+	return "yellow"
+}
+
+// diffValue returns a field value of a change on a single line
+func diffValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
 func printDiff(before, after string, to io.Writer) {
 	diffs := difflib.Diff(strings.Split(before, "\n"), strings.Split(after, "\n"))
 
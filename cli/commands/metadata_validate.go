@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/metadata"
+	"github.com/hasura/graphql-engine/cli/metadata/actions"
+	"github.com/hasura/graphql-engine/cli/metadata/schema"
+	"github.com/hasura/graphql-engine/cli/metadata/tables"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/version"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// metadataFiles are the files of the metadata directory by the top level
+// key of the metadata they hold, errors of other keys are reported in the
+// metadata directory
+var metadataFiles = map[string]string{
+	"version":           "version.yaml",
+	"tables":            "tables.yaml",
+	"functions":         "functions.yaml",
+	"query_collections": "query_collections.yaml",
+	"allowlist":         "allow_list.yaml",
+	"remote_schemas":    "remote_schemas.yaml",
+	"actions":           "actions.yaml",
+	"cron_triggers":     "cron_triggers.yaml",
+}
+
+func newMetadataValidateCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &metadataValidateOptions{
+		EC:     ec,
+		Output: os.Stdout,
+	}
+
+	metadataValidateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the metadata files without a server",
+		Long: `Build the metadata from the metadata files and validate it against the metadata schema, without contacting a server. Each error shows the file and the path of the invalid value in the metadata.
+
+The types of actions are defined in actions.graphql, which can only be converted to metadata by a server, so only the actions in actions.yaml are validated.`,
+		Example: `  # Validate the metadata files:
+  hasura metadata validate
+
+  # Show the errors as json:
+  hasura metadata validate -o json`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the endpoint is not needed
+			cmd.Root().PersistentPreRun(cmd, args)
+			ec.Viper = viper.New()
+			err := ec.Prepare()
+			if err != nil {
+				return err
+			}
+			err = ec.ValidateProject()
+			if err != nil {
+				return err
+			}
+			// validate the metadata of every feature in the schema
+			ec.Version.ServerFeatureFlags = &version.ServerFeatureFlags{
+				HasAction:       true,
+				HasCronTriggers: true,
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputFormatText, outputFormatJSON:
+				return nil
+			}
+			return fmt.Errorf("invalid output format %q, must be one of %s or %s", opts.output, outputFormatText, outputFormatJSON)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			errs, err := opts.run()
+			if err != nil {
+				return err
+			}
+			if err := opts.print(errs); err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("metadata is invalid, found %d error(s)", len(errs))
+			}
+			if opts.output == outputFormatText {
+				ec.Logger.Info("Metadata is valid")
+			}
+			return nil
+		},
+	}
+
+	f := metadataValidateCmd.Flags()
+	f.StringVarP(&opts.output, "output", "o", outputFormatText, "output format for the errors (text, json)")
+
+	return metadataValidateCmd
+}
+
+type metadataValidateOptions struct {
+	EC     *cli.ExecutionContext
+	Output io.Writer
+
+	output string
+	// tableFiles holds the file of each table, as the tables can be split
+	// into a file per table
+	tableFiles []string
+}
+
+// metadataValidationError is an error of the metadata in a file
+type metadataValidationError struct {
+	File    string `json:"file"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (o *metadataValidateOptions) run() ([]metadataValidationError, error) {
+	meta, files, err := o.build()
+	if err != nil {
+		return nil, err
+	}
+	errs, err := schema.Validate(meta)
+	if err != nil {
+		return nil, err
+	}
+	validationErrors := make([]metadataValidationError, 0, len(errs))
+	for _, e := range errs {
+		file, ok := files[e.Key()]
+		if !ok {
+			file = files[""]
+		}
+		if tableFile, ok := o.tableFile(e.Path); ok {
+			file = tableFile
+		}
+		validationErrors = append(validationErrors, metadataValidationError{
+			File:    o.relativePath(file),
+			Path:    e.Path,
+			Message: e.Message,
+		})
+	}
+	return validationErrors, nil
+}
+
+// build builds the metadata with the metadata plugins, and returns the file
+// of each top level key of the metadata. The file of the metadata itself is
+// the one of the empty key.
+func (o *metadataValidateOptions) build() (yaml.MapSlice, map[string]string, error) {
+	var meta yaml.MapSlice
+	files := map[string]string{"": o.EC.MetadataDir}
+	for _, plg := range migrate.GetMetadataPluginsWithDir(o.EC) {
+		switch plg := plg.(type) {
+		case *metadata.MetadataConfig:
+			file, err := plg.GetExistingMetadataFile()
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed getting metadata file")
+			}
+			if err := plg.Build(&meta); err != nil {
+				return nil, nil, errors.Wrapf(err, "cannot build metadata from %s", file)
+			}
+			files[""] = file
+			continue
+		case *actions.ActionConfig:
+			// the actions plugin needs a server to convert actions.graphql
+			if err := o.buildActions(&meta, files); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		built := make(map[string]bool, len(meta))
+		for _, item := range meta {
+			if key, ok := item.Key.(string); ok {
+				built[key] = true
+			}
+		}
+		if err := plg.Build(&meta); err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				o.EC.Logger.Debugf("metadata file for %s was not found, assuming an empty file", plg.Name())
+				continue
+			}
+			return nil, nil, errors.Wrap(err, fmt.Sprintf("cannot build %s from metadata", plg.Name()))
+		}
+		for _, item := range meta {
+			key, ok := item.Key.(string)
+			if !ok || built[key] {
+				continue
+			}
+			if file, ok := metadataFiles[key]; ok {
+				files[key] = filepath.Join(o.EC.MetadataDir, file)
+			}
+		}
+		if tableConfig, ok := plg.(*tables.TableConfig); ok {
+			o.tableFiles = tableConfig.Files()
+		}
+	}
+	return meta, files, nil
+}
+
+// buildActions adds the actions in actions.yaml to the metadata. The custom
+// types in actions.yaml are left out, as their fields are in actions.graphql.
+func (o *metadataValidateOptions) buildActions(meta *yaml.MapSlice, files map[string]string) error {
+	actionsFile := filepath.Join(o.EC.MetadataDir, metadataFiles["actions"])
+	data, err := ioutil.ReadFile(actionsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var items yaml.MapSlice
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return errors.Wrapf(err, "cannot parse %s", actionsFile)
+	}
+	for _, item := range items {
+		if key, ok := item.Key.(string); ok && key == "actions" {
+			files[key] = actionsFile
+			*meta = append(*meta, item)
+		}
+	}
+	return nil
+}
+
+// tableFile returns the file of the table an error at path is in
+func (o *metadataValidateOptions) tableFile(path string) (string, bool) {
+	const prefix = "$.tables["
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	end := strings.Index(path[len(prefix):], "]")
+	if end < 0 {
+		return "", false
+	}
+	i, err := strconv.Atoi(path[len(prefix) : len(prefix)+end])
+	if err != nil || i < 0 || i >= len(o.tableFiles) {
+		return "", false
+	}
+	return o.tableFiles[i], true
+}
+
+func (o *metadataValidateOptions) print(errs []metadataValidationError) error {
+	if o.output == outputFormatJSON {
+		data, err := json.MarshalIndent(errs, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "cannot marshal errors")
+		}
+		_, err = o.Output.Write(append(data, '\n'))
+		return err
+	}
+	for _, e := range errs {
+		fmt.Fprintf(o.Output, "%s: %s: %s\n", e.File, e.Path, e.Message)
+	}
+	return nil
+}
+
+// relativePath returns the path of a metadata file relative to the project directory
+func (o *metadataValidateOptions) relativePath(file string) string {
+	if rel, err := filepath.Rel(o.EC.ExecutionDirectory, file); err == nil {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
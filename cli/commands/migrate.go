@@ -37,6 +37,15 @@ func NewMigrateCmd(ec *cli.ExecutionContext) *cobra.Command {
 		newMigrateStatusCmd(ec),
 		newMigrateCreateCmd(ec),
 		newMigrateSquashCmd(ec),
+		newMigrateForceCmd(ec),
+		newMigrateRepairCmd(ec),
+		newMigrateUnlockCmd(ec),
+		newMigrateRebaseCmd(ec),
+		newMigrateHistoryCmd(ec),
+		newMigrateMoveStateCmd(ec),
+		newMigrateBaselineCmd(ec),
+		newMigrateLintCmd(ec),
+		newMigrateTestCmd(ec),
 	)
 
 	f := migrateCmd.PersistentFlags()
@@ -47,12 +56,14 @@ func NewMigrateCmd(ec *cli.ExecutionContext) *cobra.Command {
 	f.MarkDeprecated("access-key", "use --admin-secret instead")
 	f.Bool("insecure-skip-tls-verify", false, "skip TLS verification and disable cert checking (default: false)")
 	f.String("certificate-authority", "", "path to a cert file for the certificate authority")
+	f.String("lock-timeout", "", "time to wait for the migrations lock held by another process, e.g. 30s (default: 10s)")
 
 	util.BindPFlag(v, "endpoint", f.Lookup("endpoint"))
 	util.BindPFlag(v, "admin_secret", f.Lookup("admin-secret"))
 	util.BindPFlag(v, "access_key", f.Lookup("access-key"))
 	util.BindPFlag(v, "insecure_skip_tls_verify", f.Lookup("insecure-skip-tls-verify"))
 	util.BindPFlag(v, "certificate_authority", f.Lookup("certificate-authority"))
+	util.BindPFlag(v, "migrations_lock_timeout", f.Lookup("lock-timeout"))
 
 	return migrateCmd
 }
@@ -51,7 +51,22 @@ func newMigrateApplyCmd(ec *cli.ExecutionContext) *cobra.Command {
   hasura migrate apply --type down --version "<version>"
 
   # Rollback all migrations:
-  hasura migrate apply --down all`,
+  hasura migrate apply --down all
+
+  # Apply and record each migration separately, so that the ones applied
+  # before a failure are kept:
+  hasura migrate apply --per-migration
+
+  # Apply migrations older than the last applied version, e.g. after
+  # merging branches which both added migrations:
+  hasura migrate apply --allow-out-of-order
+
+  # Refuse to apply migrations if an applied migration was modified locally:
+  hasura migrate apply --strict
+
+  # Print the sql and metadata queries which would be sent to the server,
+  # and write the bulk request to a file for review:
+  hasura migrate apply --dry-run --plan --plan-file plan.json`,
 		SilenceUsage: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			err := ec.Prepare()
@@ -64,7 +79,15 @@ func newMigrateApplyCmd(ec *cli.ExecutionContext) *cobra.Command {
 			if opts.dryRun && opts.SkipExecution {
 				return errors.New("both --skip-execution and --dry-run flags cannot be used together")
 			}
-			if !opts.dryRun {
+			if (opts.plan || opts.planFile != "") && !opts.dryRun {
+				return errors.New("--plan and --plan-file flags can only be used with --dry-run")
+			}
+			// an explicit --per-migration overrides migrations_apply_mode
+			if !cmd.Flags().Changed("per-migration") {
+				opts.perMigration = ec.Config.MigrationsApplyMode == cli.MigrationsApplyModePerMigration
+			}
+			// progress is logged for each migration, don't hide it behind the spinner
+			if !opts.dryRun && !opts.perMigration {
 				opts.EC.Spin("Applying migrations...")
 			}
 			err := opts.Run()
@@ -100,7 +123,13 @@ func newMigrateApplyCmd(ec *cli.ExecutionContext) *cobra.Command {
 	f.BoolVar(&opts.SkipExecution, "skip-execution", false, "skip executing the migration action, but mark them as applied")
 	f.StringVar(&opts.MigrationType, "type", "up", "type of migration (up, down) to be used with version flag")
 
-	f.BoolVar(&opts.dryRun, "dry-run", false, "print the names of migrations which are going to be applied")
+	f.BoolVar(&opts.dryRun, "dry-run", false, "print the names of migrations which are going to be applied, along with the rendered sql if migrations_templating is enabled")
+	f.BoolVar(&opts.plan, "plan", false, "with --dry-run, print the sql and metadata queries of the bulk request for each migration, including the migrations state bookkeeping")
+	f.StringVar(&opts.planFile, "plan-file", "", "with --dry-run, write the bulk request to this file as json")
+	f.BoolVar(&opts.allowOutOfOrder, "allow-out-of-order", false, "apply unapplied migrations older than the last applied version, in version order")
+	f.BoolVar(&opts.strict, "strict", false, "refuse to apply migrations if any applied migration was modified after it was applied")
+	f.BoolVar(&opts.perMigration, "per-migration", false, "apply and record each migration separately instead of in a single bulk request (default: value of migrations_apply_mode in config)")
+	migrateApplyCmd.MarkFlagFilename("plan-file")
 	return migrateApplyCmd
 }
 
@@ -115,6 +144,12 @@ type MigrateApplyOptions struct {
 	GotoVersion   string
 	SkipExecution bool
 	dryRun        bool
+	perMigration  bool
+	strict        bool
+	plan          bool
+	planFile      string
+
+	allowOutOfOrder bool
 }
 
 func (o *MigrateApplyOptions) Run() error {
@@ -129,6 +164,17 @@ func (o *MigrateApplyOptions) Run() error {
 	}
 	migrateDrv.SkipExecution = o.SkipExecution
 	migrateDrv.DryRun = o.dryRun
+	migrateDrv.PerMigration = o.perMigration
+	migrateDrv.AllowOutOfOrder = o.allowOutOfOrder
+	// show the rendered sql when migrations are templates
+	migrateDrv.PrintSQL = o.EC.Config.MigrationsTemplating
+	migrateDrv.ShowPlan = o.plan
+	migrateDrv.PlanFile = o.planFile
+	if o.strict {
+		if err := migrateDrv.CheckModified(); err != nil {
+			return err
+		}
+	}
 
 	return ExecuteMigration(migrationType, migrateDrv, step)
 }
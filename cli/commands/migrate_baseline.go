@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateBaselineCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateBaselineOptions{
+		EC: ec,
+	}
+	migrateBaselineCmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Mark all local migrations up to a version as applied without executing them",
+		Long:  "Mark every local migration up to and including a version as applied on the database without executing it. Use this to start using migrations on an existing database which already has the changes of those migrations.",
+		Example: `  # Mark all migrations up to 1550925483858 as applied:
+  hasura migrate baseline --version 1550925483858
+
+  # List the migrations which would be marked as applied:
+  hasura migrate baseline --version 1550925483858 --dry-run
+
+  # Skip the confirmation, e.g. in CI:
+  hasura migrate baseline --version 1550925483858 --yes`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run()
+		},
+	}
+
+	f := migrateBaselineCmd.Flags()
+	f.Uint64Var(&opts.version, "version", 0, "mark migrations up to and including this version as applied")
+	f.BoolVar(&opts.dryRun, "dry-run", false, "list the migrations which would be marked as applied")
+	f.BoolVarP(&opts.yes, "yes", "y", false, "mark the migrations as applied without asking for confirmation")
+
+	// mark flag as required
+	migrateBaselineCmd.MarkFlagRequired("version")
+
+	return migrateBaselineCmd
+}
+
+type migrateBaselineOptions struct {
+	EC *cli.ExecutionContext
+
+	version uint64
+	dryRun  bool
+	yes     bool
+}
+
+func (o *migrateBaselineOptions) run() error {
+	o.EC.Spin("Fetching migration status...")
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		o.EC.Spinner.Stop()
+		return err
+	}
+	status, err := executeStatus(migrateDrv)
+	o.EC.Spinner.Stop()
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch migrate status")
+	}
+
+	var versions []uint64
+	for _, version := range status.Index {
+		m := status.Migrations[version]
+		if version <= o.version && m.IsPresent && !m.IsApplied {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		o.EC.Logger.Infof("all local migrations up to %d are already applied", o.version)
+		return nil
+	}
+
+	o.EC.Logger.Info("The following migrations will be marked as applied without executing them:")
+	fmt.Fprintf(os.Stdout, "%s", printBaseline(status, versions))
+	if o.dryRun {
+		return nil
+	}
+
+	if !o.yes {
+		if !o.EC.IsTerminal {
+			return errors.New("confirmation required, use --yes to mark the migrations as applied")
+		}
+		confirmation, err := util.GetYesNoPrompt(fmt.Sprintf("Mark %d migrations as applied?", len(versions)))
+		if err != nil {
+			return errors.Wrap(err, "error in getting user input")
+		}
+		if confirmation == "n" {
+			return nil
+		}
+	}
+
+	o.EC.Spin("Marking migrations as applied...")
+	err = migrateDrv.Baseline(versions)
+	o.EC.Spinner.Stop()
+	if err != nil {
+		return errors.Wrap(err, "baseline failed")
+	}
+	o.EC.Logger.Infof("marked %d migrations as applied", len(versions))
+	return nil
+}
+
+func printBaseline(status *migrate.Status, versions []uint64) *bytes.Buffer {
+	out := new(tabwriter.Writer)
+	buf := &bytes.Buffer{}
+	out.Init(buf, 0, 8, 2, ' ', 0)
+	w := util.NewPrefixWriter(out)
+	w.Write(util.LEVEL_0, "VERSION\tNAME\n")
+	for _, version := range versions {
+		w.Write(util.LEVEL_0, "%d\t%s\n", version, status.Migrations[version].Name)
+	}
+	out.Flush()
+	return buf
+}
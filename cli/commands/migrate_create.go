@@ -34,6 +34,9 @@ const migrateCreateCmdExamples = `  # Setup migration files for the first time b
   
   # Create up and down SQL migrations, providing contents as flags
   hasura migrate create migration-name --up-sql "CREATE TABLE article(id serial NOT NULL, title text NOT NULL, content text NOT NULL);"  --down-sql "DROP TABLE article;"
+
+  # Create a single migration.sql file with "-- migrate:up" and "-- migrate:down" sections
+  hasura migrate create migration-name --single-file
 `
 
 func newMigrateCreateCmd(ec *cli.ExecutionContext) *cobra.Command {
@@ -42,9 +45,11 @@ func newMigrateCreateCmd(ec *cli.ExecutionContext) *cobra.Command {
 	}
 
 	migrateCreateCmd := &cobra.Command{
-		Use:          "create [migration-name]",
-		Short:        "Create files required for a migration",
-		Long:         "Create sql and yaml files required for a migration",
+		Use:   "create [migration-name]",
+		Short: "Create files required for a migration",
+		Long: `Create sql and yaml files required for a migration.
+
+When migrations_templating is enabled in config.yaml, the sql migrations are rendered as templates with the environment variables when they are applied, e.g. {{ env "APP_ROLE" }}. Only template expressions written by hand are rendered: the dumps taken with --from-server or --sql-from-server contain no owners or privileges, and template delimiters in them are escaped, so add expressions for per-environment values to the created migration by hand.`,
 		Example:      migrateCreateCmdExamples,
 		SilenceUsage: true,
 		Args:         cobra.ExactArgs(1),
@@ -73,6 +78,7 @@ func newMigrateCreateCmd(ec *cli.ExecutionContext) *cobra.Command {
 	f.BoolVar(&opts.metaDataServer, "metadata-from-server", false, "take metadata from the server and write it as an up migration file")
 	f.StringVar(&opts.upSQL, "up-sql", "", "sql string/query that is to be used to create an up migration")
 	f.StringVar(&opts.downSQL, "down-sql", "", "sql string/query that is to be used to create a down migration")
+	f.BoolVar(&opts.singleFile, "single-file", false, "create a single migration.sql file with up and down sections instead of up.sql and down.sql")
 
 	migrateCreateCmd.MarkFlagFilename("sql-from-file")
 	migrateCreateCmd.MarkFlagFilename("metadata-from-file")
@@ -95,11 +101,13 @@ type migrateCreateOptions struct {
 	schemaNames    []string
 	upSQL          string
 	downSQL        string
+	singleFile     bool
 }
 
 func (o *migrateCreateOptions) run() (version int64, err error) {
 	timestamp := getTime()
 	createOptions := mig.New(timestamp, o.name, o.EC.MigrationDir)
+	createOptions.SingleFile = o.singleFile
 
 	if o.fromServer {
 		o.sqlServer = true
@@ -152,7 +160,12 @@ func (o *migrateCreateOptions) run() (version int64, err error) {
 		if err != nil {
 			return 0, errors.Wrap(err, "cannot fetch schema dump")
 		}
-		createOptions.SetSQLUp(string(data))
+		sql := string(data)
+		if o.EC.Config.MigrationsTemplating {
+			// the dump is saved as it is, template expressions are added by hand
+			sql = migrate.EscapeTemplate(sql)
+		}
+		createOptions.SetSQLUp(sql)
 	}
 
 	if o.flags.Changed("metadata-from-file") {
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateForceCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateForceOptions{
+		EC: ec,
+	}
+	migrateForceCmd := &cobra.Command{
+		Use:   "force <version>",
+		Short: "Mark a migration version as applied and clear its dirty state",
+		Long:  "Mark a migration version as applied on the database and clear its dirty state, without executing the migration. Use this after fixing a failed migration manually.",
+		Example: `  # Mark version 1550925483858 as applied after fixing it manually:
+  hasura migrate force 1550925483858`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return errors.Wrap(err, "not a valid version")
+			}
+			opts.version = version
+			if err := opts.run(); err != nil {
+				return errors.Wrap(err, "force failed")
+			}
+			opts.EC.Logger.Infof("version %d marked as applied", version)
+			return nil
+		},
+	}
+
+	return migrateForceCmd
+}
+
+type migrateForceOptions struct {
+	EC *cli.ExecutionContext
+
+	version uint64
+}
+
+func (o *migrateForceOptions) run() error {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return err
+	}
+	return migrateDrv.Force(o.version)
+}
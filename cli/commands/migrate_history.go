@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+	"github.com/hasura/graphql-engine/cli/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateHistoryCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateHistoryOptions{
+		EC: ec,
+	}
+	migrateHistoryCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Display the history of migrations applied and rolled back on a database",
+		Long:  "Display when each migration was applied or rolled back on the database, who did it, with which version of the cli and how long it took. The kind of an entry tells whether the migration was run (apply) or only recorded without running it (skip, force, baseline, repair).",
+		Example: `  # Show the full history:
+  hasura migrate history
+
+  # Show the history of a particular version:
+  hasura migrate history --version "<version>"
+
+  # Show rollbacks done in the last 24 hours:
+  hasura migrate history --direction down --since 24h
+
+  # Show the versions recorded by migrate force:
+  hasura migrate history --kind force
+
+  # Show the last 10 entries as JSON:
+  hasura migrate history --limit 10 --output json`,
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputFormatTable, outputFormatJSON, outputFormatYAML:
+			default:
+				return fmt.Errorf("invalid output format %q, must be one of %s, %s or %s", opts.output, outputFormatTable, outputFormatJSON, outputFormatYAML)
+			}
+			switch opts.direction {
+			case "", "up", "down":
+			default:
+				return fmt.Errorf("invalid direction %q, must be up or down", opts.direction)
+			}
+			switch opts.kind {
+			case "", database.KindApply, database.KindSkip, database.KindForce, database.KindBaseline, database.KindRepair:
+			default:
+				return fmt.Errorf("invalid kind %q, must be one of %s, %s, %s, %s or %s", opts.kind, database.KindApply, database.KindSkip, database.KindForce, database.KindBaseline, database.KindRepair)
+			}
+			if opts.sinceString != "" {
+				since, err := parseSince(opts.sinceString, time.Now())
+				if err != nil {
+					return err
+				}
+				opts.since = since
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.EC.Spin("Fetching migration history...")
+			history, err := opts.run()
+			opts.EC.Spinner.Stop()
+			if err != nil {
+				return errors.Wrap(err, "cannot fetch migrate history")
+			}
+			if opts.output == outputFormatTable {
+				fmt.Fprintf(os.Stdout, "%s", printHistory(history))
+				return nil
+			}
+			out, err := marshalHistory(history, opts.output)
+			if err != nil {
+				return errors.Wrap(err, "cannot render migrate history")
+			}
+			fmt.Fprintf(os.Stdout, "%s", out)
+			return nil
+		},
+	}
+
+	f := migrateHistoryCmd.Flags()
+	f.Uint64Var(&opts.version, "version", 0, "only show entries of this version")
+	f.StringVar(&opts.direction, "direction", "", "only show entries of this direction (up, down)")
+	f.StringVar(&opts.kind, "kind", "", "only show entries of this kind (apply, skip, force, baseline, repair)")
+	f.StringVar(&opts.appliedBy, "applied-by", "", "only show entries applied by this actor")
+	f.StringVar(&opts.sinceString, "since", "", "only show entries applied after this time, as a duration (e.g. 24h) or a RFC3339 timestamp")
+	f.IntVar(&opts.limit, "limit", 0, "only show the latest N entries")
+	f.StringVarP(&opts.output, "output", "o", outputFormatTable, "output format for the history (table, json, yaml)")
+
+	return migrateHistoryCmd
+}
+
+type migrateHistoryOptions struct {
+	EC *cli.ExecutionContext
+
+	version     uint64
+	direction   string
+	kind        string
+	appliedBy   string
+	sinceString string
+	since       time.Time
+	limit       int
+	output      string
+}
+
+func (o *migrateHistoryOptions) run() ([]database.HistoryEntry, error) {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return nil, err
+	}
+	history, err := migrateDrv.GetHistory()
+	if err != nil {
+		return nil, err
+	}
+	return o.filter(history), nil
+}
+
+func (o *migrateHistoryOptions) filter(history []database.HistoryEntry) []database.HistoryEntry {
+	filtered := make([]database.HistoryEntry, 0, len(history))
+	for _, entry := range history {
+		if o.version != 0 && entry.Version != o.version {
+			continue
+		}
+		if o.direction != "" && entry.Direction != o.direction {
+			continue
+		}
+		if o.kind != "" && entry.Kind != o.kind {
+			continue
+		}
+		if o.appliedBy != "" && entry.AppliedBy != o.appliedBy {
+			continue
+		}
+		if !o.since.IsZero() && entry.AppliedAt.Before(o.since) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	if o.limit > 0 && len(filtered) > o.limit {
+		filtered = filtered[len(filtered)-o.limit:]
+	}
+	return filtered
+}
+
+// parseSince parses a duration before now or a RFC3339 timestamp
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q, must be a duration (e.g. 24h) or a RFC3339 timestamp", since)
+	}
+	return t, nil
+}
+
+func printHistory(history []database.HistoryEntry) *bytes.Buffer {
+	out := new(tabwriter.Writer)
+	buf := &bytes.Buffer{}
+	out.Init(buf, 0, 8, 2, ' ', 0)
+	w := util.NewPrefixWriter(out)
+	w.Write(util.LEVEL_0, "VERSION\tDIRECTION\tKIND\tAPPLIED AT\tAPPLIED BY\tCLI VERSION\tDURATION\n")
+	for _, entry := range history {
+		duration := "-"
+		if entry.Duration != 0 {
+			duration = entry.Duration.String()
+		}
+		kind := entry.Kind
+		if kind == "" {
+			kind = "-"
+		}
+		w.Write(util.LEVEL_0, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Version,
+			entry.Direction,
+			kind,
+			entry.AppliedAt.Local().Format(time.RFC3339),
+			entry.AppliedBy,
+			entry.CLIVersion,
+			duration,
+		)
+	}
+	out.Flush()
+	return buf
+}
+
+// historyEntryOutput is the machine readable form of a history entry
+type historyEntryOutput struct {
+	Version    uint64    `json:"version"`
+	Direction  string    `json:"direction"`
+	Kind       string    `json:"kind"`
+	AppliedAt  time.Time `json:"applied_at"`
+	AppliedBy  string    `json:"applied_by"`
+	CLIVersion string    `json:"cli_version"`
+	DurationMs *int64    `json:"duration_ms"`
+}
+
+func marshalHistory(history []database.HistoryEntry, format string) ([]byte, error) {
+	entries := make([]historyEntryOutput, 0, len(history))
+	for _, entry := range history {
+		output := historyEntryOutput{
+			Version:    entry.Version,
+			Direction:  entry.Direction,
+			Kind:       entry.Kind,
+			AppliedAt:  entry.AppliedAt,
+			AppliedBy:  entry.AppliedBy,
+			CLIVersion: entry.CLIVersion,
+		}
+		if entry.Duration != 0 {
+			durationMs := int64(entry.Duration / time.Millisecond)
+			output.DurationMs = &durationMs
+		}
+		entries = append(entries, output)
+	}
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == outputFormatYAML {
+		return yaml.JSONToYAML(out)
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/migrate/lint"
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+	"github.com/hasura/graphql-engine/cli/migrate/source/file"
+	"github.com/hasura/graphql-engine/cli/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const outputFormatSARIF = "sarif"
+
+func newMigrateLintCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateLintOptions{
+		EC: ec,
+	}
+	migrateLintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check migrations for destructive and locking SQL",
+		Long: `Check the up.sql of the migrations which are not applied on the server yet for statements which lose data or lock tables, and for missing down migrations. With --all, every local migration is checked, without connecting to the server.
+
+A rule can be suppressed for a statement by a "-- lint:ignore <rule>" comment before or on the same line as the statement, and for the whole migration by a "-- lint:ignore-file <rule>" comment. Multiple rules are separated by commas, "all" suppresses every rule.`,
+		Example: `  # Check the migrations which are not applied on the server:
+  hasura migrate lint
+
+  # Check all local migrations, including the applied ones:
+  hasura migrate lint --all
+
+  # Write the findings as SARIF to annotate the migrations in CI:
+  hasura migrate lint --output sarif > lint.sarif
+
+  # Fail on warnings too:
+  hasura migrate lint --fail-on warning`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the server is only needed for the applied versions
+			if !opts.all {
+				return cmd.Parent().PersistentPreRunE(cmd, args)
+			}
+			cmd.Root().PersistentPreRun(cmd, args)
+			ec.Viper = viper.New()
+			err := ec.Prepare()
+			if err != nil {
+				return err
+			}
+			return ec.ValidateProject()
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputFormatTable, outputFormatJSON, outputFormatSARIF:
+			default:
+				return fmt.Errorf("invalid output format %q, must be one of %s, %s or %s", opts.output, outputFormatTable, outputFormatJSON, outputFormatSARIF)
+			}
+			switch opts.failOn {
+			case string(lint.SeverityError), string(lint.SeverityWarning), "none":
+			default:
+				return fmt.Errorf("invalid value %q for --fail-on, must be one of %s, %s or none", opts.failOn, lint.SeverityError, lint.SeverityWarning)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.EC.Spin("Checking migrations...")
+			findings, err := opts.run()
+			opts.EC.Spinner.Stop()
+			if err != nil {
+				return err
+			}
+			out, err := opts.marshal(findings)
+			if err != nil {
+				return errors.Wrap(err, "cannot render findings")
+			}
+			fmt.Fprintf(os.Stdout, "%s", out)
+			if len(findings) == 0 && opts.output == outputFormatTable {
+				opts.EC.Logger.Info("no issues found")
+			}
+			return checkFindings(findings, opts.failOn)
+		},
+	}
+
+	f := migrateLintCmd.Flags()
+	f.BoolVar(&opts.all, "all", false, "check all local migrations, including the ones applied on the server")
+	f.StringVarP(&opts.output, "output", "o", outputFormatTable, "output format for the findings (table, json, sarif)")
+	f.StringVar(&opts.failOn, "fail-on", string(lint.SeverityError), "exit with a non-zero code on findings of this severity or higher (error, warning, none)")
+
+	return migrateLintCmd
+}
+
+type migrateLintOptions struct {
+	EC *cli.ExecutionContext
+
+	all    bool
+	output string
+	failOn string
+}
+
+func (o *migrateLintOptions) run() ([]lint.Finding, error) {
+	// the migrations are read from the migrations directory, the server is
+	// only asked for the applied versions unless --all is set
+	sourceDrv, err := file.New(migrate.GetFilePath(o.EC.MigrationDir).String(), o.EC.Logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open migrations directory")
+	}
+	if o.EC.Config.Version >= cli.V2 {
+		sourceDrv.DefaultParser(source.DefaultParsev2)
+	}
+	if err := sourceDrv.Scan(); err != nil {
+		return nil, errors.Wrap(err, "cannot read migrations")
+	}
+	versions := []uint64(sourceDrv.Migrations.Index)
+	if !o.all {
+		versions, err = o.pendingVersions(versions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	findings := make([]lint.Finding, 0)
+	for _, version := range versions {
+		up, upFile, upLine, err := source.ReadSQL(sourceDrv, version, source.Up)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// metadata only migration
+				continue
+			}
+			return nil, err
+		}
+		down, downFile, downLine, err := source.ReadSQL(sourceDrv, version, source.Down)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		findings = append(findings, lint.Lint(lint.Migration{
+			Version:  version,
+			UpFile:   upFile,
+			Up:       up,
+			UpLine:   upLine,
+			DownFile: downFile,
+			Down:     down,
+			DownLine: downLine,
+		})...)
+	}
+	for i := range findings {
+		findings[i].File = o.relativePath(findings[i].File)
+	}
+	return findings, nil
+}
+
+// pendingVersions returns the versions which are not applied on the server
+func (o *migrateLintOptions) pendingVersions(versions []uint64) ([]uint64, error) {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return nil, err
+	}
+	status, err := executeStatus(migrateDrv)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot fetch migrate status")
+	}
+	pending := make([]uint64, 0, len(versions))
+	for _, version := range versions {
+		if s, ok := status.Read(version); ok && s.IsApplied {
+			continue
+		}
+		pending = append(pending, version)
+	}
+	return pending, nil
+}
+
+// relativePath returns the path of a migration file relative to the project directory
+func (o *migrateLintOptions) relativePath(file string) string {
+	path := filepath.Join(o.EC.MigrationDir, file)
+	if rel, err := filepath.Rel(o.EC.ExecutionDirectory, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+func (o *migrateLintOptions) marshal(findings []lint.Finding) ([]byte, error) {
+	switch o.output {
+	case outputFormatJSON:
+		out, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	case outputFormatSARIF:
+		out, err := lint.MarshalSARIF(findings)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+	if len(findings) == 0 {
+		return nil, nil
+	}
+	return printFindings(findings).Bytes(), nil
+}
+
+func printFindings(findings []lint.Finding) *bytes.Buffer {
+	out := new(tabwriter.Writer)
+	buf := &bytes.Buffer{}
+	out.Init(buf, 0, 8, 2, ' ', 0)
+	w := util.NewPrefixWriter(out)
+	w.Write(util.LEVEL_0, "SEVERITY\tLOCATION\tRULE\tMESSAGE\n")
+	for _, f := range findings {
+		w.Write(util.LEVEL_0, "%s\t%s:%d\t%s\t%s\n",
+			f.Severity,
+			f.File,
+			f.Line,
+			f.Rule,
+			f.Message,
+		)
+	}
+	out.Flush()
+	return buf
+}
+
+// checkFindings returns an error if there are findings of severity failOn or higher
+func checkFindings(findings []lint.Finding, failOn string) error {
+	var errs, warnings int
+	for _, f := range findings {
+		switch f.Severity {
+		case lint.SeverityError:
+			errs++
+		case lint.SeverityWarning:
+			warnings++
+		}
+	}
+	switch {
+	case failOn == string(lint.SeverityError) && errs > 0,
+		failOn == string(lint.SeverityWarning) && errs+warnings > 0:
+		return fmt.Errorf("found %d errors and %d warnings", errs, warnings)
+	}
+	return nil
+}
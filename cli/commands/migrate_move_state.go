@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+	"github.com/hasura/graphql-engine/cli/migrate/database/hasuradb"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateMoveStateCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateMoveStateOptions{
+		EC: ec,
+	}
+	migrateMoveStateCmd := &cobra.Command{
+		Use:   "move-state",
+		Short: "Move the migrations state to the schema and tables set in config",
+		Long:  "Move the applied versions, settings, history and repeatable migrations from their previous location to the schema, migrations_table and settings_table set in config.yaml. The previous tables are dropped.",
+		Example: `  # Move the state from the default location after setting
+  # schema, migrations_table or settings_table in config.yaml:
+  hasura migrate move-state
+
+  # Move the state from a custom location:
+  hasura migrate move-state --from-schema project_a --from-migrations-table schema_migrations`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.EC.Spin("Moving migrations state...")
+			err := opts.run()
+			opts.EC.Spinner.Stop()
+			if err != nil {
+				return errors.Wrap(err, "cannot move migrations state")
+			}
+			opts.EC.Logger.Info("migrations state moved")
+			return nil
+		},
+	}
+
+	f := migrateMoveStateCmd.Flags()
+	f.StringVar(&opts.from.Schema, "from-schema", hasuradb.DefaultSchema, "schema of the tables to move the state from")
+	f.StringVar(&opts.from.MigrationsTable, "from-migrations-table", hasuradb.DefaultMigrationsTable, "migrations table to move the state from")
+	f.StringVar(&opts.from.SettingsTable, "from-settings-table", hasuradb.DefaultSettingsTable, "settings table to move the state from")
+
+	return migrateMoveStateCmd
+}
+
+type migrateMoveStateOptions struct {
+	EC *cli.ExecutionContext
+
+	from database.StateLocation
+}
+
+func (o *migrateMoveStateOptions) run() error {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return err
+	}
+	return migrateDrv.MoveState(o.from)
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	mig "github.com/hasura/graphql-engine/cli/migrate/cmd"
+	"github.com/hasura/graphql-engine/cli/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateRebaseCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateRebaseOptions{
+		EC: ec,
+	}
+	migrateRebaseCmd := &cobra.Command{
+		Use:   "rebase",
+		Short: "Renumber unapplied local migrations after the latest migration",
+		Long:  "Renumber the local migrations which are not applied on the database with new versions after the latest migration, keeping their names and order. Use this after merging a branch whose migrations are older than the ones already applied. Migrations applied on the database are never renumbered.",
+		Example: `  # Renumber unapplied migrations after the latest applied one:
+  hasura migrate rebase
+
+  # Print the new versions without renaming any files:
+  hasura migrate rebase --dry-run`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.newVersion = getTime()
+			return opts.run()
+		},
+	}
+
+	f := migrateRebaseCmd.Flags()
+	f.BoolVar(&opts.dryRun, "dry-run", false, "print the new versions without renaming any files")
+
+	return migrateRebaseCmd
+}
+
+type migrateRebaseOptions struct {
+	EC *cli.ExecutionContext
+
+	dryRun     bool
+	newVersion int64
+}
+
+type rebaseVersion struct {
+	version    uint64
+	newVersion uint64
+	name       string
+}
+
+func (o *migrateRebaseOptions) run() error {
+	o.EC.Spin("Fetching migration status...")
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		o.EC.Spinner.Stop()
+		return err
+	}
+	status, err := executeStatus(migrateDrv)
+	o.EC.Spinner.Stop()
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch migrate status")
+	}
+
+	versions := planRebase(status, uint64(o.newVersion))
+	if len(versions) == 0 {
+		o.EC.Logger.Info("no unapplied migrations older than the latest applied migration, nothing to rebase")
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "%s", printRebase(versions))
+	if o.dryRun {
+		return nil
+	}
+
+	// the versions may have been applied by another process since the
+	// status was read, make sure nothing applied on the server is touched
+	if err := migrateDrv.ReScan(); err != nil {
+		return errors.Wrap(err, "cannot fetch applied versions")
+	}
+	applied, err := migrateDrv.GetStatus()
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch applied versions")
+	}
+	for _, v := range versions {
+		for _, version := range []uint64{v.version, v.newVersion} {
+			if m, ok := applied.Read(version); ok && m.IsApplied {
+				return fmt.Errorf("refusing to renumber version %d to %d, version %d is applied on the database", v.version, v.newVersion, version)
+			}
+		}
+	}
+
+	if err := renumber(o.EC.MigrationDir, versions, o.EC.Logger); err != nil {
+		return err
+	}
+	o.EC.Logger.Infof("renumbered %d migrations", len(versions))
+	return nil
+}
+
+// renumber renames the migration files of the versions in directory to
+// their new versions. If renaming a version fails, the renumbered versions
+// are renamed back.
+func renumber(directory string, versions []rebaseVersion, logger *logrus.Logger) error {
+	for i, v := range versions {
+		renameOptions := mig.CreateOptions{
+			Version:   strconv.FormatUint(v.version, 10),
+			Directory: directory,
+		}
+		if err := renameOptions.Rename(int64(v.newVersion)); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				undo := mig.CreateOptions{
+					Version:   strconv.FormatUint(versions[j].newVersion, 10),
+					Directory: directory,
+				}
+				if err := undo.Rename(int64(versions[j].version)); err != nil {
+					logger.Warnf("unable to rename version %d back to %d: %v", versions[j].newVersion, versions[j].version, err)
+				}
+			}
+			return errors.Wrapf(err, "unable to renumber version %d", v.version)
+		}
+	}
+	return nil
+}
+
+// planRebase returns the new versions for the local migrations which are not
+// applied on the database, in order, if any of them is older than the latest
+// applied migration. New versions start from the timestamp from, or after
+// the latest known version if it is greater.
+func planRebase(status *migrate.Status, from uint64) []rebaseVersion {
+	var unapplied []uint64
+	var outOfOrder bool
+	var latest uint64
+	for _, version := range status.Index {
+		m := status.Migrations[version]
+		if version > latest {
+			latest = version
+		}
+		if m.IsPresent && !m.IsApplied {
+			unapplied = append(unapplied, version)
+			outOfOrder = outOfOrder || m.IsOutOfOrder
+		}
+	}
+	if !outOfOrder {
+		return nil
+	}
+
+	if from <= latest {
+		from = latest + 1
+	}
+	versions := make([]rebaseVersion, 0, len(unapplied))
+	for i, version := range unapplied {
+		versions = append(versions, rebaseVersion{
+			version:    version,
+			newVersion: from + uint64(i),
+			name:       status.Migrations[version].Name,
+		})
+	}
+	return versions
+}
+
+func printRebase(versions []rebaseVersion) *bytes.Buffer {
+	out := new(tabwriter.Writer)
+	buf := &bytes.Buffer{}
+	out.Init(buf, 0, 8, 2, ' ', 0)
+	w := util.NewPrefixWriter(out)
+	w.Write(util.LEVEL_0, "VERSION\tNEW VERSION\tNAME\n")
+	for _, v := range versions {
+		w.Write(util.LEVEL_0, "%d\t%d\t%s\n", v.version, v.newVersion, v.name)
+	}
+	out.Flush()
+	return buf
+}
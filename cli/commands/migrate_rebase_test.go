@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestPlanRebase(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   *migrate.Status
+		from     uint64
+		expected []rebaseVersion
+	}{
+		{
+			"in order",
+			testStatus(
+				&migrate.MigrationStatus{Version: 1, Name: "a", IsPresent: true, IsApplied: true},
+				&migrate.MigrationStatus{Version: 2, Name: "b", IsPresent: true},
+			),
+			100,
+			nil,
+		},
+		{
+			"out of order after the timestamp",
+			testStatus(
+				&migrate.MigrationStatus{Version: 1, Name: "a", IsPresent: true, IsApplied: true},
+				&migrate.MigrationStatus{Version: 2, Name: "b", IsPresent: true, IsOutOfOrder: true},
+				&migrate.MigrationStatus{Version: 3, Name: "c", IsPresent: true, IsApplied: true},
+				&migrate.MigrationStatus{Version: 4, Name: "d", IsPresent: true},
+			),
+			100,
+			[]rebaseVersion{{2, 100, "b"}, {4, 101, "d"}},
+		},
+		{
+			"out of order after the latest version",
+			testStatus(
+				&migrate.MigrationStatus{Version: 1, Name: "a", IsPresent: true, IsOutOfOrder: true},
+				&migrate.MigrationStatus{Version: 200, Name: "b", IsApplied: true},
+			),
+			100,
+			[]rebaseVersion{{1, 201, "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions := planRebase(tt.status, tt.from)
+			if len(versions) == 0 && len(tt.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(versions, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, versions)
+			}
+		})
+	}
+}
+
+func TestRenumber(t *testing.T) {
+	// a file name which cannot be renamed to a longer version
+	longName := "2_b" + strings.Repeat("x", 250)
+	tests := []struct {
+		name     string
+		files    []string
+		versions []rebaseVersion
+		wantErr  bool
+		expected []string
+	}{
+		{
+			"renames the versions",
+			[]string{"1_a.up.sql", "2_b.up.sql", "3_c.up.sql"},
+			[]rebaseVersion{{1, 4, "a"}, {2, 5, "b"}},
+			false,
+			[]string{"3_c.up.sql", "4_a.up.sql", "5_b.up.sql"},
+		},
+		{
+			"renames the versions back when one fails",
+			[]string{"1_a.up.sql", longName},
+			[]rebaseVersion{{1, 1000000000000, "a"}, {2, 1000000000001, "b"}},
+			true,
+			[]string{"1_a.up.sql", longName},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rebase")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, name := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			logger, _ := test.NewNullLogger()
+			err = renumber(dir, tt.versions, logger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got %v", tt.wantErr, err)
+			}
+			entries, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, e := range entries {
+				files = append(files, e.Name())
+			}
+			sort.Strings(files)
+			if !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("expected files %v, got %v", tt.expected, files)
+			}
+		})
+	}
+}
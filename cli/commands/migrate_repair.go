@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateRepairCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateRepairOptions{
+		EC: ec,
+	}
+	migrateRepairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Remove dirty migration versions from the database",
+		Long:  "Remove the migration versions marked as dirty from the database, so that they are applied again on the next migrate apply. Make sure that partial changes made by the failed migration are reverted first. With --checksums, save the checksums of the local files of applied migrations which were modified instead, e.g. after squashing migrations with migrate squash --to.",
+		Example: `  # Remove dirty versions after reverting the partial changes manually:
+  hasura migrate repair
+
+  # Accept the local files of modified migrations as the applied ones:
+  hasura migrate repair --checksums`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := opts.run()
+			if err != nil {
+				if err == migrate.ErrNoChange {
+					if opts.checksums {
+						opts.EC.Logger.Info("no modified migrations found")
+					} else {
+						opts.EC.Logger.Info("no dirty migrations found")
+					}
+					return nil
+				}
+				return errors.Wrap(err, "repair failed")
+			}
+			for _, version := range versions {
+				if opts.checksums {
+					opts.EC.Logger.Infof("saved checksum of version %d", version)
+				} else {
+					opts.EC.Logger.Infof("removed dirty version %d", version)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := migrateRepairCmd.Flags()
+	f.BoolVar(&opts.checksums, "checksums", false, "save the checksums of the modified migrations instead of removing dirty versions")
+
+	return migrateRepairCmd
+}
+
+type migrateRepairOptions struct {
+	EC *cli.ExecutionContext
+
+	checksums bool
+}
+
+func (o *migrateRepairOptions) run() ([]uint64, error) {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return nil, err
+	}
+	if o.checksums {
+		return migrateDrv.RepairChecksums()
+	}
+	return migrateDrv.Repair()
+}
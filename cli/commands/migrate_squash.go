@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/hasura/graphql-engine/cli/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -25,25 +28,54 @@ func newMigrateSquashCmd(ec *cli.ExecutionContext) *cobra.Command {
 	migrateSquashCmd := &cobra.Command{
 		Use:   "squash",
 		Short: "(PREVIEW) Squash multiple migrations into a single one",
-		Long:  "(PREVIEW) Squash multiple migrations leading upto the latest one into a single migration file",
+		Long:  "(PREVIEW) Squash multiple migrations leading upto the latest one, or upto the version given by --to, into a single migration file. The migrations of a config v2 project are squashed by concatenating their SQL, leaving out the statements creating objects which are dropped again in the squashed range.",
 		Example: `  # NOTE: This command is in PREVIEW, correctness is not guaranteed and the usage may change.
 
   # squash all migrations from version 123 to the latest one:
   hasura migrate squash --from 123
 
   # Add a name for the new squashed migration
-  hasura migrate squash --name "<name>" --from 123`,
+  hasura migrate squash --name "<name>" --from 123
+
+  # squash the migrations from version 123 to version 456, the squashed
+  # migration replaces them with version 456. The checksum of version 456 is
+  # saved on the server, run "hasura migrate repair --checksums" on the other
+  # servers where it is applied:
+  hasura migrate squash --from 123 --to 456
+
+  # Verify that the squashed migration results in the same schema and metadata
+  # as the original ones, by applying them to two disposable servers:
+  hasura migrate squash --from 123 --verify --verify-original-endpoint "http://localhost:8081" --verify-squashed-endpoint "http://localhost:8082"`,
 		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.verify && (opts.verifyOriginalEndpoint == "" || opts.verifySquashedEndpoint == "") {
+				return errors.New("--verify requires --verify-original-endpoint and --verify-squashed-endpoint")
+			}
+			if opts.verify && opts.verifyOriginalEndpoint == opts.verifySquashedEndpoint {
+				return errors.New("--verify-original-endpoint and --verify-squashed-endpoint must be different servers")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.newVersion = getTime()
+			if opts.to != 0 {
+				// keep the squashed migration in the place of the range
+				opts.newVersion = int64(opts.to)
+			}
 			return opts.run()
 		},
 	}
 
 	f := migrateSquashCmd.Flags()
 	f.Uint64Var(&opts.from, "from", 0, "start squashing from this version")
+	f.Uint64Var(&opts.to, "to", 0, "squash up to and including this version, the squashed migration takes this version (default: latest version)")
 	f.StringVar(&opts.name, "name", "squashed", "name for the new squashed migration")
 	f.BoolVar(&opts.deleteSource, "delete-source", false, "delete the source files after squashing without any confirmation")
+	f.BoolVar(&opts.verify, "verify", false, "apply the original migrations and the squashed migration to two disposable servers and compare the resulting schema and metadata")
+	f.StringVar(&opts.verifyOriginalEndpoint, "verify-original-endpoint", "", "http(s) endpoint of a disposable Hasura GraphQL Engine on which the original migrations are applied")
+	f.StringVar(&opts.verifySquashedEndpoint, "verify-squashed-endpoint", "", "http(s) endpoint of a disposable Hasura GraphQL Engine on which the squashed migration is applied")
+	f.StringVar(&opts.verifyAdminSecret, "verify-admin-secret", "", "admin secret of the verify endpoints (default: admin secret of the project)")
+	f.StringSliceVar(&opts.verifySchemaNames, "verify-schema", []string{"public"}, "name of Postgres schema to compare. provide multiple schemas with a comma separated list e.g. --verify-schema public,user")
 
 	// mark flag as required
 	migrateSquashCmd.MarkFlagRequired("from")
@@ -55,49 +87,103 @@ type migrateSquashOptions struct {
 	EC *cli.ExecutionContext
 
 	from       uint64
+	to         uint64
 	name       string
 	newVersion int64
 
 	deleteSource bool
+
+	verify                 bool
+	verifyOriginalEndpoint string
+	verifySquashedEndpoint string
+	verifyAdminSecret      string
+	verifySchemaNames      []string
 }
 
 func (o *migrateSquashOptions) run() error {
 	o.EC.Logger.Warnln("This command is currently experimental and hence in preview, correctness of squashed migration is not guaranteed!")
-	o.EC.Spin(fmt.Sprintf("Squashing migrations from %d to latest...", o.from))
+	to := "latest"
+	if o.to != 0 {
+		to = strconv.FormatUint(o.to, 10)
+	}
+	o.EC.Spin(fmt.Sprintf("Squashing migrations from %d to %s...", o.from, to))
 	defer o.EC.Spinner.Stop()
 	migrateDrv, err := migrate.NewMigrate(o.EC, true)
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize migrations driver")
 	}
 
-	versions, err := mig.SquashCmd(migrateDrv, o.from, o.newVersion, o.name, o.EC.MigrationDir)
+	// the squashed migration is created next to the migrations directory,
+	// so that it can replace the squashed migrations at once
+	stagingDir, err := ioutil.TempDir(filepath.Dir(o.EC.MigrationDir), ".squash-")
+	if err != nil {
+		return errors.Wrap(err, "unable to create directory for the squashed migration")
+	}
+	defer os.RemoveAll(stagingDir)
+
+	squash := mig.SquashCmd
+	if o.EC.Config.Version == cli.V2 {
+		// the migrations of a config v2 project only have sql
+		squash = mig.SquashSQLCmd
+	}
+	versions, err := squash(migrateDrv, o.from, o.to, o.newVersion, o.name, stagingDir)
 	o.EC.Spinner.Stop()
 	if err != nil {
 		return errors.Wrap(err, "unable to squash migrations")
 	}
 
-	// squashed migration is generated
 	// TODO: capture keyboard interrupt and offer to delete the squashed migration
 
-	o.EC.Logger.Infof("Created '%d_%s' after squashing '%d' till '%d'", o.newVersion, o.name, versions[0], versions[len(versions)-1])
+	if o.verify {
+		if err := o.runVerify(versions, stagingDir); err != nil {
+			// the squashed migration cannot be trusted
+			return errors.Wrap(err, "verifying squashed migration failed")
+		}
+		o.EC.Logger.Info("Verified that the squashed migration results in the same schema and metadata")
+	}
 
 	if !o.deleteSource {
 		ok := ask2confirmDeleteMigrations(versions, o.EC.Logger)
 		if !ok {
+			if o.to != 0 {
+				// the squashed migration takes the version of the last squashed migration
+				o.EC.Logger.Infof("The squashed migration replaces version %d, it is only created along with deleting the source files", o.to)
+				return nil
+			}
+			err = mig.ReplaceCmd(o.EC.MigrationDir, stagingDir, nil)
+			if err != nil {
+				return errors.Wrap(err, "unable to create squashed migration")
+			}
+			o.EC.Logger.Infof("Created '%d_%s' after squashing '%d' till '%d'", o.newVersion, o.name, versions[0], versions[len(versions)-1])
 			return nil
 		}
 	}
 
-	for _, v := range versions {
-		delOptions := mig.CreateOptions{
-			Version:   strconv.FormatInt(v, 10),
-			Directory: o.EC.MigrationDir,
-		}
-		err = delOptions.Delete()
-		if err != nil {
-			return errors.Wrap(err, "unable to delete source file")
-		}
+	err = mig.ReplaceCmd(o.EC.MigrationDir, stagingDir, versions)
+	if err != nil {
+		return errors.Wrap(err, "unable to replace source files with squashed migration")
+	}
+	o.EC.Logger.Infof("Created '%d_%s' after squashing '%d' till '%d'", o.newVersion, o.name, versions[0], versions[len(versions)-1])
+	if o.to != 0 {
+		return o.repairChecksum(migrateDrv)
+	}
+	return nil
+}
+
+// repairChecksum saves the checksum of the squashed migration if its version
+// is applied, which would be reported as modified otherwise
+func (o *migrateSquashOptions) repairChecksum(migrateDrv *migrate.Migrate) error {
+	if err := migrateDrv.ReScan(); err != nil {
+		return errors.Wrap(err, "unable to read the squashed migration")
+	}
+	_, err := migrateDrv.RepairChecksums(o.to)
+	if err == migrate.ErrNoChange {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to save the checksum of version %d, run 'hasura migrate repair --checksums' to save it", o.to)
 	}
+	o.EC.Logger.Infof("Saved the checksum of the squashed version %d on the server, run 'hasura migrate repair --checksums' on the other servers where it is applied", o.to)
 	return nil
 }
 
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	mig "github.com/hasura/graphql-engine/cli/migrate/cmd"
+)
+
+// squashTarget is a disposable server on which the migrations
+// are applied to verify a squash
+type squashTarget struct {
+	name         string
+	endpoint     string
+	schemaDump   string
+	metadata     map[string]string
+	migrationDir string
+}
+
+// runVerify applies the original migrations and the squashed migration in
+// stagingDir to the two verify endpoints and compares the resulting schema and metadata
+func (o *migrateSquashOptions) runVerify(versions []int64, stagingDir string) error {
+	tmpDir, err := ioutil.TempDir("", "hasura-squash-verify-")
+	if err != nil {
+		return errors.Wrap(err, "cannot create temp directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	original := &squashTarget{
+		name:         "original",
+		endpoint:     o.verifyOriginalEndpoint,
+		migrationDir: filepath.Join(tmpDir, "original"),
+	}
+	squashed := &squashTarget{
+		name:         "squashed",
+		endpoint:     o.verifySquashedEndpoint,
+		migrationDir: filepath.Join(tmpDir, "squashed"),
+	}
+	if err := copyMigrations(o.EC.MigrationDir, original.migrationDir, nil); err != nil {
+		return err
+	}
+	if err := copySquashedMigrations(o.EC.MigrationDir, stagingDir, squashed.migrationDir, versions); err != nil {
+		return err
+	}
+
+	for _, target := range []*squashTarget{original, squashed} {
+		if err := o.applyToTarget(target); err != nil {
+			return errors.Wrapf(err, "%s migrations on %s", target.name, target.endpoint)
+		}
+	}
+
+	if compareTargets(original, squashed, o.EC.Logger, os.Stdout) {
+		return errors.New("the squashed migration does not result in the same schema and metadata as the original migrations")
+	}
+	return nil
+}
+
+// compareTargets prints the differences in the schema and metadata of the
+// targets to w, and returns true if there are any
+func compareTargets(original, squashed *squashTarget, logger *logrus.Logger, w io.Writer) bool {
+	var failed bool
+	if original.schemaDump != squashed.schemaDump {
+		logger.Error("the schema of the squashed migration differs from the original migrations:")
+		printDiff(original.schemaDump, squashed.schemaDump, w)
+		failed = true
+	}
+	files := make(map[string]bool)
+	for name := range original.metadata {
+		files[name] = true
+	}
+	for name := range squashed.metadata {
+		files[name] = true
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if original.metadata[name] != squashed.metadata[name] {
+			logger.Errorf("the metadata %s of the squashed migration differs from the original migrations:", name)
+			printDiff(original.metadata[name], squashed.metadata[name], w)
+			failed = true
+		}
+	}
+	return failed
+}
+
+// applyToTarget applies the migrations of the target and exports the
+// resulting schema and metadata
+func (o *migrateSquashOptions) applyToTarget(target *squashTarget) error {
+	ec := *o.EC
+	config := *o.EC.Config
+	config.ServerConfig.Endpoint = target.endpoint
+	if o.verifyAdminSecret != "" {
+		config.ServerConfig.AdminSecret = o.verifyAdminSecret
+	}
+	if err := config.ServerConfig.ParseEndpoint(); err != nil {
+		return errors.Wrap(err, "invalid endpoint")
+	}
+	ec.Config = &config
+	ec.HGEHeaders = make(map[string]string)
+	if config.ServerConfig.AdminSecret != "" {
+		ec.HGEHeaders[cli.GetAdminSecretHeaderName(ec.Version)] = config.ServerConfig.AdminSecret
+	}
+	ec.MigrationDir = target.migrationDir
+
+	migrateDrv, err := migrate.NewMigrate(&ec, true)
+	if err != nil {
+		return err
+	}
+	status, err := executeStatus(migrateDrv)
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch migrate status")
+	}
+	for _, version := range status.Index {
+		if status.Migrations[version].IsApplied {
+			return errors.New("the server already has applied migrations, squash can only be verified on a fresh server")
+		}
+	}
+
+	o.EC.Spin(fmt.Sprintf("Applying %s migrations...", target.name))
+	err = migrateDrv.Up()
+	if err == migrate.ErrNoChange {
+		err = nil
+	}
+	if err == nil && config.Version == cli.V2 && ec.MetadataDir != "" {
+		// the project metadata has to be consistent with the schema
+		err = migrateDrv.ApplyMetadata()
+	}
+	o.EC.Spinner.Stop()
+	if err != nil {
+		return errors.Wrap(err, "apply failed")
+	}
+
+	o.EC.Spin(fmt.Sprintf("Exporting %s schema and metadata...", target.name))
+	defer o.EC.Spinner.Stop()
+	dump, err := migrateDrv.ExportSchemaDump(o.verifySchemaNames)
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch schema dump")
+	}
+	target.schemaDump = normalizeSchemaDump(dump)
+	files, err := migrateDrv.ExportMetadata()
+	if err != nil {
+		return errors.Wrap(err, "cannot export metadata")
+	}
+	target.metadata = make(map[string]string)
+	for name, content := range files {
+		if content == nil {
+			// removed files are not part of the metadata
+			continue
+		}
+		target.metadata[filepath.Base(name)] = string(content)
+	}
+	return nil
+}
+
+// copyMigrations copies the migrations directory src to dst,
+// except for the files of the excluded versions
+func copyMigrations(src, dst string, exclude []int64) error {
+	if err := util.CopyDir(src, dst); err != nil {
+		return errors.Wrap(err, "cannot copy migrations")
+	}
+	for _, version := range exclude {
+		options := mig.CreateOptions{
+			Version:   strconv.FormatInt(version, 10),
+			Directory: dst,
+		}
+		if err := options.Delete(); err != nil {
+			return errors.Wrapf(err, "cannot remove version %d", version)
+		}
+	}
+	return nil
+}
+
+// copySquashedMigrations copies the migrations directory src to dst with the
+// squashed migration in staging instead of the versions it replaces
+func copySquashedMigrations(src, staging, dst string, versions []int64) error {
+	if err := copyMigrations(src, dst, versions); err != nil {
+		return err
+	}
+	staged, err := ioutil.ReadDir(staging)
+	if err != nil {
+		return errors.Wrap(err, "cannot read squashed migration")
+	}
+	for _, fi := range staged {
+		if err := util.CopyDir(filepath.Join(staging, fi.Name()), filepath.Join(dst, fi.Name())); err != nil {
+			return errors.Wrap(err, "cannot copy squashed migration")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestCopySquashedMigrations(t *testing.T) {
+	root, err := ioutil.TempDir("", "squash-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	src := filepath.Join(root, "migrations")
+	staging := filepath.Join(root, "staging")
+	for _, name := range []string{
+		"migrations/1_a/up.sql",
+		"migrations/2_b/up.sql",
+		"migrations/2_b/down.sql",
+		"migrations/3_c/up.sql",
+		"staging/2_squashed/up.sql",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		copy     func(dst string) error
+		expected []string
+	}{
+		{
+			"original",
+			func(dst string) error { return copyMigrations(src, dst, nil) },
+			[]string{"1_a", "2_b", "3_c"},
+		},
+		{
+			"squashed",
+			func(dst string) error { return copySquashedMigrations(src, staging, dst, []int64{1, 2}) },
+			[]string{"2_squashed", "3_c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(root, tt.name)
+			if err := tt.copy(dst); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := ioutil.ReadDir(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+	// the project migrations are left as they are
+	if _, err := os.Stat(filepath.Join(src, "1_a", "up.sql")); err != nil {
+		t.Errorf("expected the project migrations to be unchanged: %v", err)
+	}
+}
+
+func TestCompareTargets(t *testing.T) {
+	original := &squashTarget{
+		schemaDump: "CREATE TABLE public.a ();",
+		metadata:   map[string]string{"tables.yaml": "- table: a\n", "version.yaml": "version: 2\n"},
+	}
+	tests := []struct {
+		name     string
+		squashed *squashTarget
+		differ   bool
+	}{
+		{
+			"same",
+			&squashTarget{
+				schemaDump: "CREATE TABLE public.a ();",
+				metadata:   map[string]string{"tables.yaml": "- table: a\n", "version.yaml": "version: 2\n"},
+			},
+			false,
+		},
+		{
+			"different schema",
+			&squashTarget{
+				schemaDump: "CREATE TABLE public.b ();",
+				metadata:   map[string]string{"tables.yaml": "- table: a\n", "version.yaml": "version: 2\n"},
+			},
+			true,
+		},
+		{
+			"missing metadata file",
+			&squashTarget{
+				schemaDump: "CREATE TABLE public.a ();",
+				metadata:   map[string]string{"version.yaml": "version: 2\n"},
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			var out bytes.Buffer
+			if differ := compareTargets(original, tt.squashed, logger, &out); differ != tt.differ {
+				t.Fatalf("expected the targets to differ: %t, got %t", tt.differ, differ)
+			}
+			if tt.differ && (out.Len() == 0 || len(hook.Entries) == 0) {
+				t.Error("expected the differences to be reported")
+			}
+			if !tt.differ && (out.Len() != 0 || len(hook.Entries) != 0) {
+				t.Errorf("expected nothing to be reported, got %s", out.String())
+			}
+		})
+	}
+}
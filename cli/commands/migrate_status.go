@@ -2,10 +2,12 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"text/tabwriter"
 
+	"github.com/ghodss/yaml"
 	"github.com/hasura/graphql-engine/cli/util"
 
 	"github.com/hasura/graphql-engine/cli"
@@ -25,8 +27,21 @@ func newMigrateStatusCmd(ec *cli.ExecutionContext) *cobra.Command {
   hasura migrate status --admin-secret "<your-admin-secret>"
 
   # Check status on a different server:
-  hasura migrate status --endpoint "<endpoint>"`,
+  hasura migrate status --endpoint "<endpoint>"
+
+  # Print the status as JSON:
+  hasura migrate status --output json
+
+  # Exit with a non-zero code if there are unapplied, dirty or missing migrations:
+  hasura migrate status --fail-on-pending`,
 		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputFormatTable, outputFormatJSON, outputFormatYAML:
+				return nil
+			}
+			return fmt.Errorf("invalid output format %q, must be one of %s, %s or %s", opts.output, outputFormatTable, outputFormatJSON, outputFormatYAML)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.EC.Spin("Fetching migration status...")
 			status, err := opts.Run()
@@ -34,17 +49,50 @@ func newMigrateStatusCmd(ec *cli.ExecutionContext) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			buf := printStatus(status)
-			fmt.Fprintf(os.Stdout, "%s", buf)
+			switch opts.output {
+			case outputFormatJSON, outputFormatYAML:
+				out, err := marshalStatus(status, opts.output)
+				if err != nil {
+					return errors.Wrap(err, "cannot render migrate status")
+				}
+				fmt.Fprintf(os.Stdout, "%s", out)
+			default:
+				buf := printStatus(status)
+				fmt.Fprintf(os.Stdout, "%s", buf)
+				for _, version := range status.Index {
+					if status.Migrations[version].IsModified {
+						opts.EC.Logger.Warnf("migration %d was modified after it was applied", version)
+					}
+					if status.Migrations[version].IsOutOfOrder {
+						opts.EC.Logger.Warnf("migration %d is older than the last applied migration and is not applied, use migrate apply --allow-out-of-order to apply it", version)
+					}
+				}
+			}
+			if opts.failOnPending {
+				return checkPending(status)
+			}
 			return nil
 		},
 	}
 
+	f := migrateStatusCmd.Flags()
+	f.StringVarP(&opts.output, "output", "o", outputFormatTable, "output format for the status (table, json, yaml)")
+	f.BoolVar(&opts.failOnPending, "fail-on-pending", false, "exit with a non-zero code if local migrations are not applied or dirty, or applied migrations are not present locally")
+
 	return migrateStatusCmd
 }
 
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+	outputFormatYAML  = "yaml"
+)
+
 type MigrateStatusOptions struct {
 	EC *cli.ExecutionContext
+
+	output        string
+	failOnPending bool
 }
 
 func (o *MigrateStatusOptions) Run() (*migrate.Status, error) {
@@ -69,14 +117,127 @@ func printStatus(status *migrate.Status) *bytes.Buffer {
 		w.Write(util.LEVEL_0, "%d\t%s\t%s\t%s\n",
 			version,
 			status.Migrations[version].Name,
-			convertBool(status.Migrations[version].IsPresent),
-			convertBool(status.Migrations[version].IsApplied),
+			convertSourceStatus(status.Migrations[version]),
+			convertDatabaseStatus(status.Migrations[version]),
 		)
 	}
+	if len(status.Repeatables) > 0 {
+		w.Write(util.LEVEL_0, "\nREPEATABLE\tSOURCE STATUS\tDATABASE STATUS\tAPPLIED HASH\n")
+		for _, r := range status.Repeatables {
+			w.Write(util.LEVEL_0, "%s\t%s\t%s\t%s\n",
+				r.Name,
+				convertBool(r.IsPresent),
+				convertRepeatableStatus(r),
+				shortChecksum(r.AppliedChecksum),
+			)
+		}
+	}
 	out.Flush()
 	return buf
 }
 
+// migrationStatusOutput is the machine readable status of a migration.
+// migrate.Status is served as is to the console, keyed by version and
+// without names, so the cli lists the migrations in version order instead.
+type migrationStatusOutput struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	*migrate.MigrationStatus
+}
+
+// repeatableStatusOutput is the machine readable status of a repeatable migration
+type repeatableStatusOutput struct {
+	*migrate.RepeatableStatus
+	Pending bool `json:"pending"`
+}
+
+type statusOutput struct {
+	Migrations  []migrationStatusOutput  `json:"migrations"`
+	Repeatables []repeatableStatusOutput `json:"repeatables"`
+}
+
+func marshalStatus(status *migrate.Status, format string) ([]byte, error) {
+	migrations := make([]migrationStatusOutput, 0, len(status.Index))
+	for _, version := range status.Index {
+		m := status.Migrations[version]
+		migrations = append(migrations, migrationStatusOutput{version, m.Name, m})
+	}
+	repeatables := make([]repeatableStatusOutput, 0, len(status.Repeatables))
+	for _, r := range status.Repeatables {
+		repeatables = append(repeatables, repeatableStatusOutput{r, r.IsPending()})
+	}
+	out, err := json.MarshalIndent(statusOutput{migrations, repeatables}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == outputFormatYAML {
+		return yaml.JSONToYAML(out)
+	}
+	return append(out, '\n'), nil
+}
+
+// checkPending returns an error if there are migrations which are present
+// only locally or only on the database, or which failed and are dirty
+func checkPending(status *migrate.Status) error {
+	var notApplied, dirty, notPresent int
+	for _, version := range status.Index {
+		m := status.Migrations[version]
+		switch {
+		case m.IsDirty:
+			dirty++
+		case m.IsPresent && !m.IsApplied:
+			notApplied++
+		case m.IsApplied && !m.IsPresent:
+			notPresent++
+		}
+	}
+	for _, r := range status.Repeatables {
+		if r.IsPending() {
+			notApplied++
+		}
+	}
+	if notApplied == 0 && dirty == 0 && notPresent == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d migrations not applied on the database, %d dirty migrations and %d applied migrations not present locally", notApplied, dirty, notPresent)
+}
+
+func convertSourceStatus(m *migrate.MigrationStatus) string {
+	if m.IsModified {
+		return "Modified"
+	}
+	return convertBool(m.IsPresent)
+}
+
+func convertDatabaseStatus(m *migrate.MigrationStatus) string {
+	if m.IsDirty {
+		return "Dirty"
+	}
+	if m.IsOutOfOrder {
+		return "Out of Order"
+	}
+	return convertBool(m.IsApplied)
+}
+
+func convertRepeatableStatus(r *migrate.RepeatableStatus) string {
+	if r.IsApplied && r.IsPending() {
+		return "Changed"
+	}
+	return convertBool(r.IsApplied)
+}
+
+// shortChecksum returns the first characters of a checksum, which are
+// enough to tell checksums apart
+func shortChecksum(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	if checksum == "" {
+		return "-"
+	}
+	return checksum
+}
+
 func convertBool(ok bool) string {
 	switch ok {
 	case true:
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate"
+)
+
+func testStatus(migrations ...*migrate.MigrationStatus) *migrate.Status {
+	status := migrate.NewStatus()
+	for _, m := range migrations {
+		status.Append(m)
+	}
+	return status
+}
+
+func TestMarshalStatus(t *testing.T) {
+	status := testStatus(
+		&migrate.MigrationStatus{Version: 1, Name: "init", IsApplied: true, IsPresent: true, IsModified: true},
+		&migrate.MigrationStatus{Version: 2, Name: "posts", IsPresent: true},
+	)
+	status.Repeatables = append(status.Repeatables, &migrate.RepeatableStatus{Name: "views", IsPresent: true, Checksum: "b", AppliedChecksum: "a", IsApplied: true})
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			outputFormatJSON,
+			`{
+  "migrations": [
+    {
+      "version": 1,
+      "name": "init",
+      "database_status": true,
+      "source_status": true,
+      "dirty": false,
+      "modified": true,
+      "out_of_order": false
+    },
+    {
+      "version": 2,
+      "name": "posts",
+      "database_status": false,
+      "source_status": true,
+      "dirty": false,
+      "modified": false,
+      "out_of_order": false
+    }
+  ],
+  "repeatables": [
+    {
+      "name": "views",
+      "source_status": true,
+      "database_status": true,
+      "checksum": "b",
+      "applied_checksum": "a",
+      "pending": true
+    }
+  ]
+}
+`,
+		},
+		{
+			outputFormatYAML,
+			`migrations:
+- database_status: true
+  dirty: false
+  modified: true
+  name: init
+  out_of_order: false
+  source_status: true
+  version: 1
+- database_status: false
+  dirty: false
+  modified: false
+  name: posts
+  out_of_order: false
+  source_status: true
+  version: 2
+repeatables:
+- applied_checksum: a
+  checksum: b
+  database_status: true
+  name: views
+  pending: true
+  source_status: true
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := marshalStatus(status, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestCheckPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  *migrate.Status
+		wantErr bool
+	}{
+		{
+			"up to date",
+			testStatus(&migrate.MigrationStatus{Version: 1, IsApplied: true, IsPresent: true}),
+			false,
+		},
+		{
+			"not applied",
+			testStatus(&migrate.MigrationStatus{Version: 1, IsPresent: true}),
+			true,
+		},
+		{
+			"not present locally",
+			testStatus(&migrate.MigrationStatus{Version: 1, IsApplied: true}),
+			true,
+		},
+		{
+			"dirty",
+			testStatus(&migrate.MigrationStatus{Version: 1, IsApplied: true, IsPresent: true, IsDirty: true}),
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPending(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
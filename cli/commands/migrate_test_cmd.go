@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateTestCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateTestOptions{
+		EC: ec,
+	}
+	migrateTestCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Check that migrations can be rolled back by applying them up, down and up again",
+		Long: `Apply the migrations which are not applied on the server up, then down and then up again, one version at a time. The schema is dumped before and after each step. The test fails if the schema after the down migrations differs from the schema before the up migrations, or if applying the up migrations again results in a different schema.
+
+The down migrations are executed on the server, so the test is only run against a disposable database given by --test-endpoint, never against the endpoint of the project.`,
+		Example: `  # Test the pending migrations on a disposable server:
+  hasura migrate test --test-endpoint "http://localhost:8081"
+
+  # Test a range of versions and compare the myschema schema:
+  hasura migrate test --test-endpoint "http://localhost:8081" --from 1550925483858 --to 1550931962927 --schema myschema`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the test endpoint replaces the endpoint of the project
+			if opts.testEndpoint == "" {
+				return errors.New("--test-endpoint is required, the down migrations are executed on the server")
+			}
+			if err := cmd.Flags().Set("endpoint", opts.testEndpoint); err != nil {
+				return err
+			}
+			if opts.testAdminSecret != "" {
+				if err := cmd.Flags().Set("admin-secret", opts.testAdminSecret); err != nil {
+					return err
+				}
+			}
+			return cmd.Parent().PersistentPreRunE(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run()
+		},
+	}
+
+	f := migrateTestCmd.Flags()
+	f.StringVar(&opts.testEndpoint, "test-endpoint", "", "http(s) endpoint of a Hasura GraphQL Engine on a disposable database to run the test against")
+	f.StringVar(&opts.testAdminSecret, "test-admin-secret", "", "admin secret of the test endpoint")
+	f.Uint64Var(&opts.from, "from", 0, "test the pending migrations from this version")
+	f.Uint64Var(&opts.to, "to", 0, "test the pending migrations up to and including this version")
+	f.StringSliceVar(&opts.schemaNames, "schema", []string{"public"}, "name of Postgres schema to compare. provide multiple schemas with a comma separated list e.g. --schema public,user")
+
+	return migrateTestCmd
+}
+
+type migrateTestOptions struct {
+	EC *cli.ExecutionContext
+
+	testEndpoint    string
+	testAdminSecret string
+	from            uint64
+	to              uint64
+	schemaNames     []string
+}
+
+func (o *migrateTestOptions) run() error {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return err
+	}
+	status, err := executeStatus(migrateDrv)
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch migrate status")
+	}
+	versions, err := o.versions(status)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		o.EC.Logger.Info("nothing to test")
+		return nil
+	}
+
+	before, err := o.schemaDump(migrateDrv)
+	if err != nil {
+		return err
+	}
+	if err := o.apply(migrateDrv, versions, "up"); err != nil {
+		return err
+	}
+	afterUp, err := o.schemaDump(migrateDrv)
+	if err != nil {
+		return err
+	}
+
+	reversed := make([]uint64, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		reversed = append(reversed, versions[i])
+	}
+	if err := o.apply(migrateDrv, reversed, "down"); err != nil {
+		return err
+	}
+	afterDown, err := o.schemaDump(migrateDrv)
+	if err != nil {
+		return err
+	}
+	if afterDown != before {
+		printDiff(before, afterDown, os.Stdout)
+		return errors.New("the schema after the down migrations differs from the schema before the up migrations")
+	}
+
+	if err := o.apply(migrateDrv, versions, "up"); err != nil {
+		return err
+	}
+	afterReapply, err := o.schemaDump(migrateDrv)
+	if err != nil {
+		return err
+	}
+	if afterReapply != afterUp {
+		printDiff(afterUp, afterReapply, os.Stdout)
+		return errors.New("applying the up migrations again resulted in a different schema")
+	}
+
+	o.EC.Logger.Infof("%d migrations can be applied and rolled back", len(versions))
+	return nil
+}
+
+// versions returns the local migrations to test, which must not be
+// applied on the server
+func (o *migrateTestOptions) versions(status *migrate.Status) ([]uint64, error) {
+	var versions []uint64
+	for _, version := range status.Index {
+		m := status.Migrations[version]
+		if !m.IsPresent || version < o.from || (o.to != 0 && version > o.to) {
+			continue
+		}
+		if m.IsApplied {
+			if o.from != 0 || o.to != 0 {
+				return nil, fmt.Errorf("version %d is already applied on the server, migrations can only be tested if they are not applied", version)
+			}
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// apply applies the versions one by one in the direction
+func (o *migrateTestOptions) apply(migrateDrv *migrate.Migrate, versions []uint64, direction string) error {
+	for _, version := range versions {
+		o.EC.Spin(fmt.Sprintf("Applying %s migration %d...", direction, version))
+		err := migrateDrv.Migrate(version, direction)
+		if err == nil {
+			// read the applied versions again for the next step
+			err = migrateDrv.ReScan()
+		}
+		o.EC.Spinner.Stop()
+		if err != nil {
+			return errors.Wrapf(err, "applying %s migration %d failed", direction, version)
+		}
+		o.EC.Logger.Infof("applied %s migration %d", direction, version)
+	}
+	return nil
+}
+
+func (o *migrateTestOptions) schemaDump(migrateDrv *migrate.Migrate) (string, error) {
+	o.EC.Spin("Fetching schema dump...")
+	dump, err := migrateDrv.ExportSchemaDump(o.schemaNames)
+	o.EC.Spinner.Stop()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot fetch schema dump")
+	}
+	return normalizeSchemaDump(dump), nil
+}
+
+// normalizeSchemaDump removes the comments and empty lines of a schema dump,
+// which differ between dumps of the same schema
+func normalizeSchemaDump(dump []byte) string {
+	var lines []string
+	for _, line := range strings.Split(string(dump), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,44 @@
+package commands
+
+import "testing"
+
+func TestNormalizeSchemaDump(t *testing.T) {
+	before := `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+CREATE TABLE public.users (
+    id integer NOT NULL
+);
+`
+	tests := []struct {
+		name  string
+		after string
+		equal bool
+	}{
+		{
+			"comments, empty lines and trailing spaces",
+			"-- Dumped from database version 12.4\r\n\r\nSET statement_timeout = 0;  \r\nCREATE TABLE public.users (\r\n    id integer NOT NULL\r\n);\r\n\r\n",
+			true,
+		},
+		{
+			"column left behind by a down migration",
+			"SET statement_timeout = 0;\nCREATE TABLE public.users (\n    id integer NOT NULL,\n    name text\n);\n",
+			false,
+		},
+		{
+			"indentation",
+			"SET statement_timeout = 0;\nCREATE TABLE public.users (\nid integer NOT NULL\n);\n",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal := normalizeSchemaDump([]byte(before)) == normalizeSchemaDump([]byte(tt.after))
+			if equal != tt.equal {
+				t.Errorf("expected the dumps to be equal: %t, got %t", tt.equal, equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateUnlockCmd(ec *cli.ExecutionContext) *cobra.Command {
+	opts := &migrateUnlockOptions{
+		EC: ec,
+	}
+	migrateUnlockCmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Release the migrations lock held by another process",
+		Long:  "Release the migrations lock, whichever process holds it. Use this when a process which was applying migrations was killed or lost its connection before releasing the lock. Make sure the process holding the lock is no longer running, as migrations applied at the same time can leave the database in an inconsistent state.",
+		Example: `  # Release the lock left behind by an interrupted migrate apply:
+  hasura migrate unlock`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			holder, err := opts.run()
+			if err != nil {
+				return errors.Wrap(err, "cannot release the migrations lock")
+			}
+			if holder == "" {
+				opts.EC.Logger.Info("migrations are not locked")
+				return nil
+			}
+			opts.EC.Logger.Infof("released the migrations lock held by %s", holder)
+			return nil
+		},
+	}
+
+	return migrateUnlockCmd
+}
+
+type migrateUnlockOptions struct {
+	EC *cli.ExecutionContext
+}
+
+func (o *migrateUnlockOptions) run() (string, error) {
+	migrateDrv, err := migrate.NewMigrate(o.EC, true)
+	if err != nil {
+		return "", err
+	}
+	return migrateDrv.ForceUnLock()
+}
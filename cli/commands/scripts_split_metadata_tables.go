@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/metadata/tables"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+func newScriptsSplitMetadataTablesCmd(ec *cli.ExecutionContext) *cobra.Command {
+	v := viper.New()
+	scriptsSplitMetadataTablesCmd := &cobra.Command{
+		Use:   "split-metadata-tables",
+		Short: "Split tables.yaml into a file per table",
+		Long: `Split the tables in the metadata directory into a file per table by executing the following actions:
+1. Writes each table in tables.yaml to tables/<schema>.<table>.yaml
+2. Re-writes tables.yaml to include the table files
+3. Sets metadata_tables_layout to per-table in config.yaml, so that the tables are exported to a file per table
+
+The schema and the table are separated by a dot rather than an underscore, as both names can contain underscores, e.g. public.user_roles.yaml. Characters which are not allowed in file names, dots and % are percent-encoded, e.g. the table "a.b" of schema public is written to public.a%2Eb.yaml.
+`,
+		Example: `  # Split tables.yaml into a file per table:
+  hasura scripts split-metadata-tables`,
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the endpoint is not needed
+			ec.Viper = v
+			err := ec.Prepare()
+			if err != nil {
+				return err
+			}
+			return ec.ValidateProject()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ec.Config.Version != cli.V2 || ec.MetadataDir == "" {
+				return fmt.Errorf("this script can be executed only when the current config version is 2 and has a metadata directory")
+			}
+			if ec.Config.MetadataTablesLayout == cli.MetadataTablesLayoutPerTable {
+				return fmt.Errorf("the tables are already split into a file per table")
+			}
+			ec.Spin("Splitting tables...")
+			defer ec.Spinner.Stop()
+			// read the tables in the current layout
+			tableConfig := tables.New(ec, ec.MetadataDir)
+			var metadata yaml.MapSlice
+			err := tableConfig.Build(&metadata)
+			if err != nil {
+				return errors.Wrap(err, "cannot read tables")
+			}
+			tableConfig.Layout = cli.MetadataTablesLayoutPerTable
+			files, err := tableConfig.Export(metadata)
+			if err != nil {
+				return errors.Wrap(err, "cannot split tables")
+			}
+			for name, content := range files {
+				if content == nil {
+					continue
+				}
+				err = os.MkdirAll(filepath.Dir(name), os.ModePerm)
+				if err != nil {
+					return errors.Wrap(err, "cannot create tables directory")
+				}
+				err = ioutil.WriteFile(name, content, 0644)
+				if err != nil {
+					return errors.Wrapf(err, "cannot write %s", name)
+				}
+			}
+			ec.Spin("Writing new config file...")
+			// Read the config from config.yaml
+			cfgByt, err := ioutil.ReadFile(ec.ConfigFile)
+			if err != nil {
+				return errors.Wrap(err, "cannot read config file")
+			}
+			var cfg cli.Config
+			err = yaml.Unmarshal(cfgByt, &cfg)
+			if err != nil {
+				return errors.Wrap(err, "cannot parse config file")
+			}
+			cfg.MetadataTablesLayout = cli.MetadataTablesLayoutPerTable
+			err = ec.WriteConfig(&cfg)
+			if err != nil {
+				return errors.Wrap(err, "cannot write config file")
+			}
+			ec.Spinner.Stop()
+			ec.Logger.Infof("Tables are split into %s", filepath.Join(ec.MetadataDir, "tables"))
+			return nil
+		},
+	}
+	return scriptsSplitMetadataTablesCmd
+}
@@ -0,0 +1,359 @@
+// Package diff compares two sets of Hasura metadata object by object, and
+// merges overlays into metadata the same way.
+//
+// Tables, functions and the objects defined on them are matched by their
+// names instead of their position, so that reordering them is not a change.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	gyaml "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ChangeType is the kind of change of a metadata object
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Change is an added, removed or changed metadata object
+type Change struct {
+	Type ChangeType `json:"type"`
+	// Kind of the object, e.g. table or select_permission
+	Kind string `json:"kind"`
+	// Name of the object, e.g. the role of a permission
+	Name string `json:"name"`
+	// Parent is the name of the object this object is defined on,
+	// e.g. the table of a permission
+	Parent string `json:"parent,omitempty"`
+	// Fields holds the changed fields of a changed object
+	Fields []FieldChange `json:"fields,omitempty"`
+
+	// Before and After are the object on either side, nil for an added or
+	// removed object respectively
+	Before map[string]interface{} `json:"-"`
+	After  map[string]interface{} `json:"-"`
+	// ParentKey is the key of the parent object as it is in the metadata,
+	// e.g. the {schema, name} of the table of a permission
+	ParentKey interface{} `json:"-"`
+}
+
+// FieldChange is a change of a field of a metadata object. Before is nil
+// for added fields and After is nil for removed ones.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Type returns whether the field is added, removed or changed
+func (f FieldChange) Type() ChangeType {
+	switch {
+	case f.Before == nil:
+		return Added
+	case f.After == nil:
+		return Removed
+	}
+	return Changed
+}
+
+// keyedList is a list of metadata objects which are matched by a key
+type keyedList struct {
+	// kind of the objects in the list
+	kind string
+	// field of the objects holding their key
+	field string
+	// qualified is set when the key is a {schema, name} object
+	qualified bool
+	// children are the keyed lists inside the objects of the list
+	children map[string]keyedList
+}
+
+// name returns the name of an object in the list
+func (l keyedList) name(object map[string]interface{}) string {
+	if l.qualified {
+		return QualifiedName(object[l.field])
+	}
+	name, ok := object[l.field].(string)
+	if !ok {
+		data, _ := json.Marshal(object[l.field])
+		return string(data)
+	}
+	return name
+}
+
+// QualifiedName returns schema.name for a {schema, name} object, public.name
+// for a bare name, and the value as json otherwise
+func QualifiedName(v interface{}) string {
+	switch name := v.(type) {
+	case string:
+		return "public." + name
+	case map[string]interface{}:
+		schema, _ := name["schema"].(string)
+		n, _ := name["name"].(string)
+		if schema == "" {
+			schema = "public"
+		}
+		return schema + "." + n
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// metadataLists are the keyed lists at the top level of the metadata
+var metadataLists = map[string]keyedList{
+	"tables": {
+		kind:      "table",
+		field:     "table",
+		qualified: true,
+		children: map[string]keyedList{
+			"object_relationships": {kind: "object_relationship", field: "name"},
+			"array_relationships":  {kind: "array_relationship", field: "name"},
+			"remote_relationships": {kind: "remote_relationship", field: "name"},
+			"computed_fields":      {kind: "computed_field", field: "name"},
+			"insert_permissions":   {kind: "insert_permission", field: "role"},
+			"select_permissions":   {kind: "select_permission", field: "role"},
+			"update_permissions":   {kind: "update_permission", field: "role"},
+			"delete_permissions":   {kind: "delete_permission", field: "role"},
+			"event_triggers":       {kind: "event_trigger", field: "name"},
+		},
+	},
+	"functions":         {kind: "function", field: "function", qualified: true},
+	"remote_schemas":    {kind: "remote_schema", field: "name"},
+	"query_collections": {kind: "query_collection", field: "name"},
+	"allowlist":         {kind: "allowlist", field: "collection"},
+	"actions": {
+		kind:  "action",
+		field: "name",
+		children: map[string]keyedList{
+			"permissions": {kind: "action_permission", field: "role"},
+		},
+	},
+	"cron_triggers": {kind: "cron_trigger", field: "name"},
+}
+
+// Diff returns the changes from the metadata before to the metadata after.
+// The metadata can be any value which marshals to yaml, e.g. a yaml.MapSlice.
+func Diff(before, after interface{}) ([]Change, error) {
+	b, err := normalize(before)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	a, err := normalize(after)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	changes := make([]Change, 0)
+	changes = diffObject(changes, Change{Type: Changed, Kind: "metadata", Before: b, After: a}, metadataLists, nil)
+	return changes, nil
+}
+
+// normalize converts metadata to maps with string keys, as json would
+func normalize(metadata interface{}) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	data, err = gyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	if normalized == nil {
+		normalized = make(map[string]interface{})
+	}
+	return normalized, nil
+}
+
+// diffObject appends the changes of an object which exists on both sides:
+// the changes of the objects in its keyed lists, and the object itself if
+// any of its other fields changed
+func diffObject(changes []Change, change Change, lists map[string]keyedList, parentKey interface{}) []Change {
+	var fields []FieldChange
+	for _, key := range sortedKeys(change.Before, change.After) {
+		if list, ok := lists[key]; ok {
+			changes = diffList(changes, list, change.Name, parentKey, change.Before[key], change.After[key])
+			continue
+		}
+		fields = diffValue(fields, key, change.Before[key], change.After[key])
+	}
+	if len(fields) > 0 {
+		change.Fields = fields
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffList appends the changes of the objects in a keyed list
+func diffList(changes []Change, list keyedList, parent string, parentKey interface{}, before, after interface{}) []Change {
+	b, bOrder := keyObjects(list, before)
+	a, aOrder := keyObjects(list, after)
+	for _, name := range bOrder {
+		if _, ok := a[name]; !ok {
+			changes = append(changes, Change{Type: Removed, Kind: list.kind, Name: name, Parent: parent, Before: b[name], ParentKey: parentKey})
+		}
+	}
+	for _, name := range aOrder {
+		if _, ok := b[name]; !ok {
+			changes = append(changes, Change{Type: Added, Kind: list.kind, Name: name, Parent: parent, After: a[name], ParentKey: parentKey})
+			continue
+		}
+		change := Change{Type: Changed, Kind: list.kind, Name: name, Parent: parent, Before: b[name], After: a[name], ParentKey: parentKey}
+		changes = diffObject(changes, change, list.children, a[name][list.field])
+	}
+	return changes
+}
+
+// keyObjects returns the objects of a keyed list by their key, along with
+// the keys in the order of the list
+func keyObjects(list keyedList, value interface{}) (map[string]map[string]interface{}, []string) {
+	objects := make(map[string]map[string]interface{})
+	var order []string
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := list.name(object)
+		if _, ok := objects[key]; !ok {
+			order = append(order, key)
+		}
+		objects[key] = object
+	}
+	return objects, order
+}
+
+// Children returns the objects defined on an added or removed object, e.g.
+// the permissions of a table, as added or removed objects themselves
+func Children(change Change) []Change {
+	list, ok := findList(change.Kind, metadataLists)
+	if !ok {
+		return nil
+	}
+	object := change.After
+	if change.Type == Removed {
+		object = change.Before
+	}
+	fields := make([]string, 0, len(list.children))
+	for field := range list.children {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var children []Change
+	for _, field := range fields {
+		child := list.children[field]
+		objects, order := keyObjects(child, object[field])
+		for _, name := range order {
+			c := Change{Type: change.Type, Kind: child.kind, Name: name, Parent: change.Name, ParentKey: object[list.field]}
+			if change.Type == Removed {
+				c.Before = objects[name]
+			} else {
+				c.After = objects[name]
+			}
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// findList returns the keyed list of the objects of a kind
+func findList(kind string, lists map[string]keyedList) (keyedList, bool) {
+	for _, list := range lists {
+		if list.kind == kind {
+			return list, true
+		}
+		if child, ok := findList(kind, list.children); ok {
+			return child, true
+		}
+	}
+	return keyedList{}, false
+}
+
+// diffValue appends the changes of a field, descending into objects
+func diffValue(fields []FieldChange, path string, before, after interface{}) []FieldChange {
+	b, bok := before.(map[string]interface{})
+	a, aok := after.(map[string]interface{})
+	if bok && aok {
+		for _, key := range sortedKeys(b, a) {
+			fields = diffValue(fields, path+"."+key, b[key], a[key])
+		}
+		return fields
+	}
+	if equal(before, after) {
+		return fields
+	}
+	return append(fields, FieldChange{Path: path, Before: before, After: after})
+}
+
+// equal compares two values, lists of scalars are compared regardless of
+// their order, as they are sets like the columns of a permission
+func equal(before, after interface{}) bool {
+	b, bok := before.([]interface{})
+	a, aok := after.([]interface{})
+	if bok && aok && len(a) == len(b) && scalars(b) && scalars(a) {
+		return reflect.DeepEqual(sortedScalars(b), sortedScalars(a))
+	}
+	return reflect.DeepEqual(before, after)
+}
+
+func scalars(list []interface{}) bool {
+	for _, v := range list {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+func sortedScalars(list []interface{}) []string {
+	sorted := make([]string, 0, len(list))
+	for _, v := range list {
+		sorted = append(sorted, fmt.Sprintf("%T:%v", v, v))
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// sortedKeys returns the keys of both objects in order
+func sortedKeys(before, after map[string]interface{}) []string {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns a description of the changed object, e.g.
+// "select_permission user on public.users"
+func (c Change) String() string {
+	var s strings.Builder
+	s.WriteString(c.Kind)
+	if c.Name != "" {
+		s.WriteString(" " + c.Name)
+	}
+	if c.Parent != "" {
+		s.WriteString(" on " + c.Parent)
+	}
+	return s.String()
+}
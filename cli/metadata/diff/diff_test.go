@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestDiff(t *testing.T) {
+	before := `
+version: 2
+tables:
+- table:
+    schema: public
+    name: users
+  select_permissions:
+  - role: user
+    permission:
+      columns: [id, name]
+      filter: {}
+  - role: guest
+    permission:
+      columns: [id]
+      filter: {}
+- table: posts
+  array_relationships:
+  - name: comments
+    using:
+      foreign_key_constraint_on:
+        column: post_id
+        table: comments
+remote_schemas:
+- name: countries
+  definition:
+    url: https://countries.example.com
+`
+	after := `
+version: 2
+tables:
+- table: posts
+  array_relationships:
+  - name: comments
+    using:
+      foreign_key_constraint_on:
+        column: post_id
+        table: comments
+- table:
+    schema: public
+    name: users
+  select_permissions:
+  - role: user
+    permission:
+      columns: [name, id]
+      filter:
+        id: {_eq: X-Hasura-User-Id}
+  - role: admin
+    permission:
+      columns: [id]
+      filter: {}
+functions:
+- function:
+    schema: public
+    name: search_posts
+remote_schemas:
+- name: countries
+  definition:
+    url: https://countries.example.com
+    timeout_seconds: 30
+`
+	var b, a yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(before), &b); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(after), &a); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Diff(b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Type: Added, Kind: "function", Name: "public.search_posts"},
+		{Type: Changed, Kind: "remote_schema", Name: "countries", Fields: []FieldChange{
+			{Path: "definition.timeout_seconds", After: float64(30)},
+		}},
+		{Type: Removed, Kind: "select_permission", Name: "guest", Parent: "public.users"},
+		{Type: Changed, Kind: "select_permission", Name: "user", Parent: "public.users", Fields: []FieldChange{
+			{Path: "permission.filter.id", After: map[string]interface{}{"_eq": "X-Hasura-User-Id"}},
+		}},
+		{Type: Added, Kind: "select_permission", Name: "admin", Parent: "public.users"},
+	}
+	for i := range changes {
+		changes[i].Before, changes[i].After, changes[i].ParentKey = nil, nil, nil
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, changes)
+	}
+
+	changes, err = Diff(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
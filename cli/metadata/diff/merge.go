@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Merge returns the metadata base with the fields of the metadata overlay.
+// Objects in keyed lists, e.g. tables and their permissions, are merged with
+// the object with the same key in base, or appended when there is none.
+// Objects are merged field by field, a null field removes the field, and
+// any other value replaces the one in base.
+func Merge(base, overlay interface{}) (map[string]interface{}, error) {
+	b, err := normalize(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	o, err := normalize(overlay)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata overlay")
+	}
+	return mergeObject(b, o, metadataLists), nil
+}
+
+// mergeObject merges the fields of overlay into base
+func mergeObject(base, overlay map[string]interface{}, lists map[string]keyedList) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		if list, ok := lists[key]; ok {
+			merged[key] = mergeList(list, merged[key], value)
+			continue
+		}
+		b, bok := merged[key].(map[string]interface{})
+		o, ook := value.(map[string]interface{})
+		if bok && ook {
+			merged[key] = mergeObject(b, o, nil)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// mergeList merges the objects of the keyed list overlay into base, in the
+// order of base
+func mergeList(list keyedList, base, overlay interface{}) interface{} {
+	baseItems, ok := base.([]interface{})
+	if !ok {
+		return overlay
+	}
+	overlayObjects, order := keyObjects(list, overlay)
+	merged := make([]interface{}, 0, len(baseItems)+len(order))
+	found := make(map[string]bool, len(order))
+	for _, item := range baseItems {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			merged = append(merged, item)
+			continue
+		}
+		name := list.name(object)
+		if o, ok := overlayObjects[name]; ok {
+			found[name] = true
+			// keep the key as it is in base, e.g. a table by its name
+			fields := make(map[string]interface{}, len(o))
+			for k, v := range o {
+				if k != list.field {
+					fields[k] = v
+				}
+			}
+			item = mergeObject(object, fields, list.children)
+		}
+		merged = append(merged, item)
+	}
+	for _, name := range order {
+		if !found[name] {
+			merged = append(merged, overlayObjects[name])
+		}
+	}
+	return merged
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMerge(t *testing.T) {
+	base := `
+version: 2
+tables:
+- table: users
+  event_triggers:
+  - name: user_created
+    definition: {enable_manual: false, insert: {columns: "*"}}
+    webhook: http://localhost:3000/user-created
+    retry_conf: {num_retries: 0}
+- table: {schema: public, name: posts}
+remote_schemas:
+- name: countries
+  definition:
+    url: http://localhost:4000/graphql
+    timeout_seconds: 60
+actions:
+- name: login
+  definition: {kind: synchronous, handler: "http://localhost:3000/login"}
+`
+	overlay := `
+tables:
+- table: {schema: public, name: users}
+  event_triggers:
+  - name: user_created
+    webhook: https://staging.example.com/user-created
+    retry_conf: null
+remote_schemas:
+- name: countries
+  definition: {url: "https://staging.example.com/graphql"}
+- name: payments
+  definition: {url: "https://payments.staging.example.com/graphql"}
+actions:
+- name: login
+  definition: {handler: "https://staging.example.com/login"}
+`
+	expected := `
+version: 2
+tables:
+- table: users
+  event_triggers:
+  - name: user_created
+    definition: {enable_manual: false, insert: {columns: "*"}}
+    webhook: https://staging.example.com/user-created
+- table: {schema: public, name: posts}
+remote_schemas:
+- name: countries
+  definition:
+    url: https://staging.example.com/graphql
+    timeout_seconds: 60
+- name: payments
+  definition: {url: "https://payments.staging.example.com/graphql"}
+actions:
+- name: login
+  definition: {kind: synchronous, handler: "https://staging.example.com/login"}
+`
+	var baseMeta, overlayMeta, expectedMeta yaml.MapSlice
+	for value, meta := range map[string]*yaml.MapSlice{base: &baseMeta, overlay: &overlayMeta, expected: &expectedMeta} {
+		if err := yaml.Unmarshal([]byte(value), meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merged, err := Merge(baseMeta, overlayMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized, err := normalize(expectedMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(merged, normalized) {
+		t.Errorf("expected\n%v\ngot\n%v", normalized, merged)
+	}
+}
@@ -0,0 +1,151 @@
+// Package overlay applies the environment specific values of the metadata,
+// e.g. the urls of remote schemas for staging, to the metadata built from
+// the metadata directory.
+//
+// The overlays of an environment are in overlays/<environment> in the
+// metadata directory, in files named like the metadata files. Each file
+// holds only the objects and fields to change, which are merged into the
+// metadata by the keys of the objects.
+package overlay
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/metadata/diff"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// directory of the overlays in the metadata directory
+	overlaysDirectory string = "overlays"
+)
+
+// overlayFiles are the top level keys of the metadata by the name of the
+// overlay file holding them. actions.yaml holds top level keys itself.
+var overlayFiles = map[string]string{
+	"tables.yaml":            "tables",
+	"functions.yaml":         "functions",
+	"query_collections.yaml": "query_collections",
+	"allow_list.yaml":        "allowlist",
+	"remote_schemas.yaml":    "remote_schemas",
+	"actions.yaml":           "",
+	"cron_triggers.yaml":     "cron_triggers",
+}
+
+type OverlayConfig struct {
+	MetadataDir string
+	// Environment whose overlays are applied
+	Environment string
+
+	logger *logrus.Logger
+}
+
+func New(ec *cli.ExecutionContext, baseDir string) *OverlayConfig {
+	return &OverlayConfig{
+		MetadataDir: baseDir,
+		Environment: ec.MetadataEnvironment,
+		logger:      ec.Logger,
+	}
+}
+
+func (o *OverlayConfig) Validate() error {
+	return nil
+}
+
+// CreateFiles creates no files, as overlays are optional
+func (o *OverlayConfig) CreateFiles() error {
+	return nil
+}
+
+// Build merges the overlays of the environment into the metadata built by
+// the plugins before it
+func (o *OverlayConfig) Build(metadata *yaml.MapSlice) error {
+	dir := filepath.Join(o.MetadataDir, overlaysDirectory, o.Environment)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		// the environment is given explicitly, so missing overlays are
+		// an error rather than an empty file
+		return fmt.Errorf("cannot read the overlays of environment %q: %v", o.Environment, err)
+	}
+	var overlay yaml.MapSlice
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		key, ok := overlayFiles[file.Name()]
+		if !ok {
+			return fmt.Errorf("unknown overlay file %s, should be one of %s", filepath.Join(dir, file.Name()), strings.Join(fileNames(), ", "))
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			var items yaml.MapSlice
+			err = yaml.Unmarshal(data, &items)
+			if err != nil {
+				return errors.Wrapf(err, "cannot parse %s", filepath.Join(dir, file.Name()))
+			}
+			overlay = append(overlay, items...)
+			continue
+		}
+		var value interface{}
+		err = yaml.Unmarshal(data, &value)
+		if err != nil {
+			return errors.Wrapf(err, "cannot parse %s", filepath.Join(dir, file.Name()))
+		}
+		overlay = append(overlay, yaml.MapItem{Key: key, Value: value})
+	}
+	o.logger.Debugf("applying the overlays of environment %s", o.Environment)
+	merged, err := diff.Merge(*metadata, overlay)
+	if err != nil {
+		return err
+	}
+	// keep the order of the metadata, and add new keys in order
+	result := make(yaml.MapSlice, 0, len(merged))
+	for _, item := range *metadata {
+		key, ok := item.Key.(string)
+		if !ok {
+			continue
+		}
+		if value, ok := merged[key]; ok {
+			result = append(result, yaml.MapItem{Key: key, Value: value})
+			delete(merged, key)
+		}
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, yaml.MapItem{Key: key, Value: merged[key]})
+	}
+	*metadata = result
+	return nil
+}
+
+// Export exports no files, the overlays are only written by hand
+func (o *OverlayConfig) Export(metadata yaml.MapSlice) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+func (o *OverlayConfig) Name() string {
+	return "overlays"
+}
+
+func fileNames() []string {
+	names := make([]string, 0, len(overlayFiles))
+	for name := range overlayFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,36 @@
+// +build ignore
+
+// gen writes the json schema of the metadata from contrib/metadata-types to
+// definitions.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+const source = "../../../contrib/metadata-types/generated/HasuraMetadataV2.json"
+
+func main() {
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimSpace(data), "", "  "); err != nil {
+		log.Fatal(err)
+	}
+	// a raw string literal cannot hold backquotes
+	schema := strings.Replace(out.String(), "`", "` + \"`\" + `", -1)
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Code generated by gen.go from %s. DO NOT EDIT.\n\n", strings.TrimPrefix(source, "../../../"))
+	fmt.Fprintf(&code, "package schema\n\n")
+	fmt.Fprintf(&code, "const metadataSchema = `%s\n`\n", schema)
+	if err := ioutil.WriteFile("definitions.go", code.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
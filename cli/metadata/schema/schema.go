@@ -0,0 +1,303 @@
+// Package schema validates metadata against the json schema of the metadata
+// in contrib/metadata-types, without a server.
+package schema
+
+//go:generate go run gen.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	gyaml "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Error is a value of the metadata which does not match the schema
+type Error struct {
+	// Path of the value in the metadata, e.g. $.tables[0].table
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Key returns the top level key of the metadata the value is in, e.g. tables
+func (e Error) Key() string {
+	key := strings.TrimPrefix(e.Path, "$.")
+	if i := strings.IndexAny(key, ".["); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// definition is the subset of json schema used by the metadata schema
+type definition struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*definition `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *definition            `json:"items"`
+	AnyOf                []*definition          `json:"anyOf"`
+	Enum                 []interface{}          `json:"enum"`
+	Title                string                 `json:"title"`
+}
+
+type document struct {
+	Definitions map[string]*definition `json:"definitions"`
+}
+
+var metadataDocument *document
+
+func load() (*document, error) {
+	if metadataDocument != nil {
+		return metadataDocument, nil
+	}
+	var doc document
+	if err := json.Unmarshal([]byte(metadataSchema), &doc); err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata schema")
+	}
+	if _, ok := doc.Definitions["HasuraMetadataV2"]; !ok {
+		return nil, errors.New("metadata schema has no HasuraMetadataV2 definition")
+	}
+	// the schema describes the permissions of an action as a single
+	// permission, they are a list of permissions
+	if action, ok := doc.Definitions["Action"]; ok {
+		if permissions, ok := action.Properties["permissions"]; ok && permissions.Type == "object" {
+			action.Properties["permissions"] = &definition{Type: "array", Items: permissions, Title: permissions.Title}
+		}
+	}
+	metadataDocument = &doc
+	return metadataDocument, nil
+}
+
+// Validate validates metadata, which can be any value which marshals to
+// yaml, e.g. a yaml.MapSlice. Fields which are not in the schema are
+// reported as errors, as they are most likely typos.
+func Validate(metadata interface{}) ([]Error, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	data, err = gyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, errors.Wrap(err, "cannot read metadata")
+	}
+	v := &validator{doc: doc}
+	return v.validate("$", value, doc.Definitions["HasuraMetadataV2"]), nil
+}
+
+type validator struct {
+	doc *document
+}
+
+// resolve follows the reference of a definition
+func (v *validator) resolve(def *definition) *definition {
+	for def.Ref != "" {
+		ref, ok := v.doc.Definitions[strings.TrimPrefix(def.Ref, "#/definitions/")]
+		if !ok {
+			return &definition{}
+		}
+		def = ref
+	}
+	return def
+}
+
+// validate returns the errors of value at path
+func (v *validator) validate(path string, value interface{}, def *definition) []Error {
+	def = v.resolve(def)
+	if len(def.AnyOf) > 0 {
+		return v.validateAnyOf(path, value, def)
+	}
+	if def.Type != "" && !hasType(value, def.Type) {
+		return []Error{{Path: path, Message: fmt.Sprintf("expected %s, got %s", def.Type, typeOf(value))}}
+	}
+	if len(def.Enum) > 0 {
+		for _, e := range def.Enum {
+			if e == value {
+				return nil
+			}
+		}
+		return []Error{{Path: path, Message: fmt.Sprintf("expected one of %s, got %s", enumValues(def.Enum), jsonValue(value))}}
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		if def.Items == nil {
+			return nil
+		}
+		var errs []Error
+		for i, item := range value {
+			errs = append(errs, v.validate(fmt.Sprintf("%s[%d]", path, i), item, def.Items)...)
+		}
+		return errs
+	case map[string]interface{}:
+		return v.validateObject(path, value, def)
+	}
+	return nil
+}
+
+func (v *validator) validateObject(path string, value map[string]interface{}, def *definition) []Error {
+	var errs []Error
+	required := make(map[string]bool, len(def.Required))
+	for _, field := range def.Required {
+		required[field] = true
+		if _, ok := value[field]; !ok {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("missing required field %q", field)})
+		}
+	}
+	// an object without properties is free-form, unless it says otherwise
+	var additional *definition
+	switch raw := strings.TrimSpace(string(def.AdditionalProperties)); raw {
+	case "":
+		if len(def.Properties) == 0 {
+			additional = &definition{}
+		}
+	case "true":
+		additional = &definition{}
+	case "false":
+	default:
+		additional = &definition{}
+		json.Unmarshal(def.AdditionalProperties, additional)
+	}
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := path + "." + key
+		if value[key] == nil && !required[key] {
+			// optional fields can be null
+			continue
+		}
+		if prop, ok := def.Properties[key]; ok {
+			errs = append(errs, v.validate(fieldPath, value[key], prop)...)
+			continue
+		}
+		if additional == nil {
+			errs = append(errs, Error{Path: fieldPath, Message: "unknown field"})
+			continue
+		}
+		errs = append(errs, v.validate(fieldPath, value[key], additional)...)
+	}
+	return errs
+}
+
+// validateAnyOf returns no errors if value matches any of the definitions,
+// and otherwise the errors of the definition it matches best
+func (v *validator) validateAnyOf(path string, value interface{}, def *definition) []Error {
+	var best []Error
+	bestDepth := -1
+	for _, option := range def.AnyOf {
+		errs := v.validate(path, value, option)
+		if len(errs) == 0 {
+			return nil
+		}
+		// the option whose errors are the deepest in the value is the one
+		// which was meant, e.g. an object with a wrong field rather than a
+		// string
+		depth := 0
+		for _, err := range errs {
+			if d := strings.Count(err.Path, ".") + strings.Count(err.Path, "["); d > depth {
+				depth = d
+			}
+		}
+		if depth > bestDepth {
+			best, bestDepth = errs, depth
+		}
+	}
+	if len(best) == 1 && best[0].Path == path {
+		var types []string
+		for _, option := range def.AnyOf {
+			types = append(types, v.describe(option))
+		}
+		return []Error{{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), typeOf(value))}}
+	}
+	return best
+}
+
+// describe returns the name of a definition in errors
+func (v *validator) describe(def *definition) string {
+	name := strings.TrimPrefix(def.Ref, "#/definitions/")
+	def = v.resolve(def)
+	switch {
+	case def.Type != "" && def.Type != "object":
+		return def.Type
+	case name != "":
+		return name
+	case def.Title != "":
+		return def.Title
+	}
+	return "object"
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+func enumValues(enum []interface{}) string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		values = append(values, jsonValue(e))
+	}
+	return strings.Join(values, ", ")
+}
+
+func jsonValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		expected []Error
+	}{
+		{
+			"valid metadata",
+			`
+version: 2
+tables:
+- table: {schema: public, name: users}
+  select_permissions:
+  - role: user
+    permission:
+      columns: "*"
+      filter: {id: {_eq: X-Hasura-User-Id}}
+    comment: null
+actions:
+- name: login
+  definition:
+    kind: synchronous
+    handler: https://example.com/login
+    type: mutation
+    arguments:
+    - {name: credentials, type: Credentials!}
+    output_type: LoginOutput
+  permissions:
+  - role: user
+  - role: anonymous
+custom_types:
+  input_objects:
+  - name: Credentials
+    fields:
+    - {name: username, type: String!}
+  objects:
+  - name: LoginOutput
+    fields:
+    - {name: user_id, type: Int!}
+    relationships:
+    - name: user
+      type: object
+      remote_table: {schema: public, name: users}
+      field_mapping: {user_id: id}
+`,
+			nil,
+		},
+		{
+			"invalid actions",
+			`
+version: 2
+tables: []
+actions:
+- name: login
+  definition:
+    handler: https://example.com/login
+  permissions:
+    role: user
+- name: logout
+  definition:
+    handler: https://example.com/logout
+  permissions:
+  - {}
+custom_types:
+  objects:
+  - name: LoginOutput
+    relationships:
+    - name: user
+      type: one
+      remote_table: users
+      field_mapping: {user_id: id}
+`,
+			[]Error{
+				{Path: "$.actions[0].permissions", Message: "expected array, got object"},
+				{Path: "$.actions[1].permissions[0]", Message: `missing required field "role"`},
+				{Path: "$.custom_types.objects[0]", Message: `missing required field "fields"`},
+				{Path: "$.custom_types.objects[0].relationships[0].type", Message: `expected one of "array", "object", got "one"`},
+			},
+		},
+		{
+			"invalid metadata",
+			`
+version: 2
+tables:
+- table: {schema: public, name: users}
+  select_permission:
+  - role: user
+  insert_permissions:
+  - role: user
+    permission:
+      columns: 10
+      check: {}
+- table: posts
+actions:
+- name: login
+  definition:
+    handler: https://example.com/login
+    type: subscription
+`,
+			[]Error{
+				{Path: "$.actions[0].definition.type", Message: `expected one of "mutation", "query", got "subscription"`},
+				{Path: "$.tables[0].insert_permissions[0].permission.columns", Message: "expected array or string, got number"},
+				{Path: "$.tables[0].select_permission", Message: "unknown field"},
+				{Path: "$.tables[1].table", Message: "expected object, got string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var metadata yaml.MapSlice
+			if err := yaml.Unmarshal([]byte(tt.metadata), &metadata); err != nil {
+				t.Fatal(err)
+			}
+			errs, err := Validate(metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Errorf("expected\n%v\ngot\n%v", tt.expected, errs)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package tables
 
 import (
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/hasura/graphql-engine/cli"
@@ -12,10 +16,20 @@ import (
 
 const (
 	fileName string = "tables.yaml"
+	// directory of the table files in the per-table layout
+	tablesDirectory string = "tables"
+	// includePrefix marks an entry of tables.yaml which includes a table file
+	includePrefix string = "!include "
 )
 
 type TableConfig struct {
 	MetadataDir string
+	// Layout of the exported tables, cli.MetadataTablesLayoutSingleFile or
+	// cli.MetadataTablesLayoutPerTable
+	Layout string
+
+	// files holds the file of each table read by the last Build
+	files []string
 
 	logger *logrus.Logger
 }
@@ -23,6 +37,7 @@ type TableConfig struct {
 func New(ec *cli.ExecutionContext, baseDir string) *TableConfig {
 	return &TableConfig{
 		MetadataDir: baseDir,
+		Layout:      ec.Config.MetadataTablesLayout,
 		logger:      ec.Logger,
 	}
 }
@@ -44,23 +59,72 @@ func (t *TableConfig) CreateFiles() error {
 	return nil
 }
 
+// tableEntry is an entry of tables.yaml, either a table or the include of a
+// table file
+type tableEntry struct {
+	include string
+	table   yaml.MapSlice
+}
+
+func (e *tableEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var include string
+	if err := unmarshal(&include); err == nil {
+		if !strings.HasPrefix(include, includePrefix) {
+			return fmt.Errorf("invalid table %q, should be a table or %q followed by the path of a table file", include, strings.TrimSpace(includePrefix))
+		}
+		e.include = strings.TrimSpace(strings.TrimPrefix(include, includePrefix))
+		return nil
+	}
+	return unmarshal(&e.table)
+}
+
+// Build reads the tables from tables.yaml, along with the table files it
+// includes, in the order of tables.yaml
 func (t *TableConfig) Build(metadata *yaml.MapSlice) error {
-	data, err := ioutil.ReadFile(filepath.Join(t.MetadataDir, fileName))
+	indexFile := filepath.Join(t.MetadataDir, fileName)
+	data, err := ioutil.ReadFile(indexFile)
 	if err != nil {
 		return err
 	}
-	item := yaml.MapItem{
-		Key:   "tables",
-		Value: []yaml.MapSlice{},
-	}
-	err = yaml.Unmarshal(data, &item.Value)
+	var entries []tableEntry
+	err = yaml.Unmarshal(data, &entries)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "cannot parse %s", indexFile)
+	}
+	tables := make([]yaml.MapSlice, 0, len(entries))
+	t.files = make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.include == "" {
+			tables = append(tables, entry.table)
+			t.files = append(t.files, indexFile)
+			continue
+		}
+		tableFile := filepath.Join(t.MetadataDir, filepath.FromSlash(entry.include))
+		data, err := ioutil.ReadFile(tableFile)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read table file included by %s", fileName)
+		}
+		var table yaml.MapSlice
+		err = yaml.Unmarshal(data, &table)
+		if err != nil {
+			return errors.Wrapf(err, "cannot parse %s", tableFile)
+		}
+		tables = append(tables, table)
+		t.files = append(t.files, tableFile)
 	}
-	*metadata = append(*metadata, item)
+	*metadata = append(*metadata, yaml.MapItem{
+		Key:   "tables",
+		Value: tables,
+	})
 	return nil
 }
 
+// Files returns the file of each table read by the last Build, in the order
+// of the tables in the metadata
+func (t *TableConfig) Files() []string {
+	return t.files
+}
+
 func (t *TableConfig) Export(metadata yaml.MapSlice) (map[string][]byte, error) {
 	var tables interface{}
 	for _, item := range metadata {
@@ -73,6 +137,9 @@ func (t *TableConfig) Export(metadata yaml.MapSlice) (map[string][]byte, error)
 	if tables == nil {
 		tables = make([]interface{}, 0)
 	}
+	if t.Layout == cli.MetadataTablesLayoutPerTable {
+		return t.exportPerTable(tables)
+	}
 	data, err := yaml.Marshal(tables)
 	if err != nil {
 		return nil, err
@@ -82,6 +149,116 @@ func (t *TableConfig) Export(metadata yaml.MapSlice) (map[string][]byte, error)
 	}, nil
 }
 
+// exportPerTable writes each table to tables/<schema>.<table>.yaml and the
+// includes of the table files to tables.yaml, sorted by file name. Table
+// files of tables which no longer exist are removed, by a nil content.
+func (t *TableConfig) exportPerTable(tables interface{}) (map[string][]byte, error) {
+	var list []interface{}
+	switch tables := tables.(type) {
+	case []interface{}:
+		list = tables
+	case []yaml.MapSlice:
+		// tables built from the metadata files
+		for _, table := range tables {
+			list = append(list, table)
+		}
+	default:
+		return nil, fmt.Errorf("invalid tables in metadata, expected a list")
+	}
+	files := make(map[string][]byte)
+	existing, err := filepath.Glob(filepath.Join(t.MetadataDir, tablesDirectory, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range existing {
+		files[file] = nil
+	}
+	includes := make([]string, 0, len(list))
+	exported := make(map[string]bool, len(list))
+	for _, table := range list {
+		name, err := TableFileName(table)
+		if err != nil {
+			return nil, err
+		}
+		if exported[name] {
+			return nil, fmt.Errorf("cannot export more than one table to %s/%s", tablesDirectory, name)
+		}
+		exported[name] = true
+		data, err := yaml.Marshal(table)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join(t.MetadataDir, tablesDirectory, name)] = data
+		includes = append(includes, includePrefix+tablesDirectory+"/"+name)
+	}
+	sort.Strings(includes)
+	data, err := yaml.Marshal(includes)
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join(t.MetadataDir, fileName)] = data
+	return files, nil
+}
+
+// unsafeFileNameChars are escaped in the names of table files, along
+// with the separator of the schema and the table and the escape character
+const unsafeFileNameChars = "/\\:*?\"<>|.%"
+
+// escapeFileName percent-encodes the characters of name which are unsafe
+// in file names, so that different names never map to the same file
+func escapeFileName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < ' ' || strings.IndexByte(unsafeFileNameChars, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// TableFileName returns the name of the file of a table in the per-table
+// layout, <schema>.<table>.yaml with the names escaped by escapeFileName
+func TableFileName(table interface{}) (string, error) {
+	var schema, name string
+	var qualified interface{}
+	switch table := table.(type) {
+	case yaml.MapSlice:
+		for _, item := range table {
+			if item.Key == "table" {
+				qualified = item.Value
+			}
+		}
+	case map[interface{}]interface{}:
+		qualified = table["table"]
+	}
+	switch qualified := qualified.(type) {
+	case string:
+		schema, name = "public", qualified
+	case yaml.MapSlice:
+		for _, item := range qualified {
+			switch item.Key {
+			case "schema":
+				schema, _ = item.Value.(string)
+			case "name":
+				name, _ = item.Value.(string)
+			}
+		}
+	case map[interface{}]interface{}:
+		schema, _ = qualified["schema"].(string)
+		name, _ = qualified["name"].(string)
+	}
+	if name == "" {
+		return "", fmt.Errorf("cannot find the name of table %v", table)
+	}
+	if schema == "" {
+		schema = "public"
+	}
+	return escapeFileName(schema) + "." + escapeFileName(name) + ".yaml", nil
+}
+
 func (t *TableConfig) Name() string {
 	return "tables"
 }
@@ -0,0 +1,122 @@
+package tables
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+func TestPerTableLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tables")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tables := `
+- table: {schema: public, name: users}
+  select_permissions:
+  - role: user
+    permission: {columns: [id], filter: {}}
+- table: authors
+- table: {schema: blog, name: posts}
+`
+	var metadata yaml.MapSlice
+	if err := yaml.Unmarshal([]byte("tables:"+tables), &metadata); err != nil {
+		t.Fatal(err)
+	}
+	// a table file of a table which no longer exists
+	if err := os.MkdirAll(filepath.Join(dir, tablesDirectory), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, tablesDirectory, "public.comments.yaml")
+	if err := ioutil.WriteFile(stale, []byte("table: comments\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &TableConfig{MetadataDir: dir, Layout: cli.MetadataTablesLayoutPerTable, logger: logrus.New()}
+	files, err := tc.Export(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, ok := files[stale]; !ok || content != nil {
+		t.Errorf("expected %s to be removed", stale)
+	}
+	expectedIndex := "- '!include tables/blog.posts.yaml'\n- '!include tables/public.authors.yaml'\n- '!include tables/public.users.yaml'\n"
+	if index := string(files[filepath.Join(dir, fileName)]); index != expectedIndex {
+		t.Errorf("expected index\n%s\ngot\n%s", expectedIndex, index)
+	}
+	for name, content := range files {
+		if content == nil {
+			if err := os.Remove(name); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(name, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var built yaml.MapSlice
+	if err := tc.Build(&built); err != nil {
+		t.Fatal(err)
+	}
+	var expected yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(`
+tables:
+- table: {schema: blog, name: posts}
+- table: authors
+- table: {schema: public, name: users}
+  select_permissions:
+  - role: user
+    permission: {columns: [id], filter: {}}
+`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	builtData, err := yaml.Marshal(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(builtData) != string(expectedData) {
+		t.Errorf("expected\n%s\ngot\n%s", expectedData, builtData)
+	}
+	expectedFiles := []string{
+		filepath.Join(dir, tablesDirectory, "blog.posts.yaml"),
+		filepath.Join(dir, tablesDirectory, "public.authors.yaml"),
+		filepath.Join(dir, tablesDirectory, "public.users.yaml"),
+	}
+	if !reflect.DeepEqual(tc.Files(), expectedFiles) {
+		t.Errorf("expected files %v, got %v", expectedFiles, tc.Files())
+	}
+}
+
+func TestTableFileName(t *testing.T) {
+	tests := []struct {
+		table    interface{}
+		expected string
+	}{
+		{yaml.MapSlice{{Key: "table", Value: "users"}}, "public.users.yaml"},
+		{yaml.MapSlice{{Key: "table", Value: yaml.MapSlice{{Key: "schema", Value: "a_b"}, {Key: "name", Value: "c"}}}}, "a_b.c.yaml"},
+		{yaml.MapSlice{{Key: "table", Value: yaml.MapSlice{{Key: "schema", Value: "a"}, {Key: "name", Value: "b_c"}}}}, "a.b_c.yaml"},
+		{yaml.MapSlice{{Key: "table", Value: yaml.MapSlice{{Key: "schema", Value: "a.b"}, {Key: "name", Value: "c/d%"}}}}, "a%2Eb.c%2Fd%25.yaml"},
+	}
+	for _, tt := range tests {
+		name, err := TableFileName(tt.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, name)
+		}
+	}
+}
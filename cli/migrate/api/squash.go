@@ -58,7 +58,7 @@ func SquashCreateAPI(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, &Response{Code: "internal_error", Message: err.Error()})
 		return
 	}
-	versions, err := cmd.SquashCmd(t, request.From, request.version, request.Name, sourceURL.Path)
+	versions, err := cmd.SquashCmd(t, request.From, 0, request.version, request.Name, sourceURL.Path)
 	if err != nil {
 		if strings.HasPrefix(err.Error(), DataAPIError) {
 			c.JSON(http.StatusBadRequest, &Response{Code: "data_api_error", Message: strings.TrimPrefix(err.Error(), DataAPIError)})
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+)
+
+// ErrModified is returned when migrations were modified locally after
+// they were applied on the database
+type ErrModified struct {
+	Versions []uint64
+}
+
+func (e ErrModified) Error() string {
+	versions := make([]string, 0, len(e.Versions))
+	for _, version := range e.Versions {
+		versions = append(versions, fmt.Sprintf("%d", version))
+	}
+	return fmt.Sprintf("migrations modified after they were applied: %s", strings.Join(versions, ", "))
+}
+
+// checksum returns the sha256 checksum of the up migration files of version
+func (m *Migrate) checksum(version uint64) (string, error) {
+	h := sha256.New()
+	for _, read := range []func(uint64) (io.ReadCloser, string, string, error){
+		m.sourceDrv.ReadUp,
+		m.sourceDrv.ReadMetaUp,
+	} {
+		r, _, _, err := read(version)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+		// separate the files, so that moving content between them
+		// changes the checksum
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// saveChecksum saves the checksum of the local up migration files of version
+// along with it, if the version is present locally
+func (m *Migrate) saveChecksum(version uint64) error {
+	directions := m.sourceDrv.GetDirections(version)
+	if !directions[source.Up] && !directions[source.MetaUp] {
+		return nil
+	}
+	checksum, err := m.checksum(version)
+	if err != nil {
+		return err
+	}
+	return m.databaseDrv.SetChecksum(int64(version), checksum)
+}
+
+// isModified returns true if the local up migration files of an applied
+// version differ from the ones which were applied
+func (m *Migrate) isModified(version uint64) (bool, error) {
+	applied, ok := m.databaseDrv.Checksum(version)
+	if !ok {
+		return false, nil
+	}
+	checksum, err := m.checksum(version)
+	if err != nil {
+		return false, err
+	}
+	return checksum != applied, nil
+}
+
+// CheckModified returns ErrModified if any of the applied migrations were
+// modified locally after they were applied
+func (m *Migrate) CheckModified() error {
+	status, err := m.GetStatus()
+	if err != nil {
+		return err
+	}
+	var versions []uint64
+	for _, version := range status.Index {
+		if status.Migrations[version].IsModified {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) > 0 {
+		return ErrModified{versions}
+	}
+	return nil
+}
+
+// RepairChecksums saves the checksums of the local files of the applied
+// versions which were modified, so that they are no longer reported as
+// modified. Only the given versions are repaired, if any. It returns the
+// versions whose checksums were saved.
+func (m *Migrate) RepairChecksums(only ...uint64) ([]uint64, error) {
+	mode, err := m.databaseDrv.GetSetting("migration_mode")
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != "true" {
+		return nil, ErrNoMigrationMode
+	}
+
+	status, err := m.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[uint64]bool)
+	for _, version := range only {
+		selected[version] = true
+	}
+	var versions []uint64
+	for _, version := range status.Index {
+		if status.Migrations[version].IsModified && (len(only) == 0 || selected[version]) {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoChange
+	}
+
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		if err := m.saveChecksum(version); err != nil {
+			m.databaseDrv.ResetQuery()
+			return nil, m.unlockErr(err)
+		}
+	}
+	return versions, m.unlockErr(nil)
+}
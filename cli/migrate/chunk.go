@@ -0,0 +1,140 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// chunkQueryOverhead is added to the size of a migration file when estimating
+// the size of its queries, for the query envelope and the migrations state
+// bookkeeping
+const chunkQueryOverhead = 1024
+
+// chunker splits the queries of the migrations into multiple bulk requests,
+// so that a request does not exceed ChunkSize bytes or ChunkCount migrations.
+// A version and its bookkeeping are always sent in the same request.
+type chunker struct {
+	m *Migrate
+
+	// migrations holds the versions and repeatable migrations queued for
+	// the current request
+	migrations []*Migration
+	// size of the queries queued for the current request
+	size int64
+	// number of queries whose size is counted
+	queued int
+	// number of requests sent
+	sent int
+}
+
+// enabled reports whether the migrations are split into chunks
+func (c *chunker) enabled() bool {
+	return !c.m.PerMigration && (c.m.ChunkSize > 0 || c.m.ChunkCount > 0)
+}
+
+// next is called before the queries of migr are queued. It sends the queued
+// queries if adding migr to them would exceed the limits.
+func (c *chunker) next(migr *Migration, applied *[]uint64) error {
+	c.record()
+	if len(c.migrations) > 0 && migr.Repeatable == "" {
+		last := c.migrations[len(c.migrations)-1]
+		if last.Repeatable == "" && last.Version == migr.Version {
+			// part of the current version
+			return nil
+		}
+	}
+
+	estimate, err := c.estimate(migr)
+	if err != nil {
+		return err
+	}
+	full := c.m.ChunkCount > 0 && len(c.migrations) >= c.m.ChunkCount
+	if c.m.ChunkSize > 0 && c.size+estimate > c.m.ChunkSize {
+		full = true
+	}
+	if full {
+		if err := c.flush(applied); err != nil {
+			return err
+		}
+	}
+	c.migrations = append(c.migrations, migr)
+	return nil
+}
+
+// flush sends the queued queries, if any. When showing a plan, the queries
+// are added to the plan instead.
+func (c *chunker) flush(applied *[]uint64) error {
+	if len(c.migrations) == 0 {
+		return nil
+	}
+	if c.m.plan != nil {
+		c.m.plan.flush(c.m.databaseDrv.Queries())
+		c.m.databaseDrv.ResetQuery()
+		c.sent++
+		c.migrations = nil
+		c.size = 0
+		c.queued = 0
+		return nil
+	}
+	if err := c.m.databaseDrv.Flush(); err != nil {
+		return ErrPartialApply{Applied: *applied, Err: err}
+	}
+	c.sent++
+	var versions int
+	for _, migr := range c.migrations {
+		if migr.Repeatable == "" {
+			*applied = append(*applied, migr.Version)
+			versions++
+		}
+	}
+	first, last := c.migrations[0], c.migrations[len(c.migrations)-1]
+	c.m.Logger.Infof("applied chunk %d with %d migration(s), from %s to %s", c.sent, versions, chunkName(first), chunkName(last))
+	c.migrations = nil
+	c.size = 0
+	c.queued = 0
+	return nil
+}
+
+// record adds the size of the queries queued since the last call
+func (c *chunker) record() {
+	queries := c.m.databaseDrv.Queries()
+	for _, query := range queries[c.queued:] {
+		data, err := json.Marshal(query)
+		if err == nil {
+			c.size += int64(len(data))
+		}
+	}
+	c.queued = len(queries)
+}
+
+// estimate returns the estimated size of the queries of migr. The body of
+// migr is read, and replaced by a reader of its content.
+func (c *chunker) estimate(migr *Migration) (int64, error) {
+	if migr.BufferedBody == nil {
+		return chunkQueryOverhead, nil
+	}
+	body, err := ioutil.ReadAll(migr.BufferedBody)
+	if err != nil {
+		return 0, err
+	}
+	migr.BufferedBody = bytes.NewReader(body)
+	if migr.FileType != "sql" {
+		return int64(len(body)) + chunkQueryOverhead, nil
+	}
+	// the sql is sent as a json string
+	data, err := json.Marshal(string(body))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)) + chunkQueryOverhead, nil
+}
+
+// chunkName returns the name of a migration in the progress of the chunks
+func chunkName(migr *Migration) string {
+	if migr.Repeatable != "" {
+		return migr.Repeatable
+	}
+	return fmt.Sprintf("%d_%s", migr.Version, migr.Identifier)
+}
@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// chunkTestDriver queues the bodies of the migrations and records the bulk
+// queries which are sent
+type chunkTestDriver struct {
+	database.Driver
+	queries []interface{}
+	sent    [][]interface{}
+}
+
+func (d *chunkTestDriver) Queries() []interface{} {
+	return d.queries
+}
+
+func (d *chunkTestDriver) ResetQuery() {
+	d.queries = nil
+}
+
+func (d *chunkTestDriver) Flush() error {
+	d.sent = append(d.sent, d.queries)
+	d.queries = nil
+	return nil
+}
+
+func TestChunker(t *testing.T) {
+	tests := []struct {
+		name       string
+		chunkSize  int64
+		chunkCount int
+		sizes      []int
+		expected   []int
+	}{
+		{"by count", 0, 2, []int{10, 10, 10, 10, 10}, []int{2, 2, 1}},
+		{"by size", chunkQueryOverhead + 2500, 0, []int{1000, 1000, 1000, 10}, []int{2, 2}},
+		{"migration larger than chunk size", 2 * chunkQueryOverhead, 0, []int{10, 5000, 10}, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := test.NewNullLogger()
+			drv := &chunkTestDriver{}
+			m := &Migrate{databaseDrv: drv, Logger: logger, ChunkSize: tt.chunkSize, ChunkCount: tt.chunkCount}
+			c := &chunker{m: m}
+			var applied []uint64
+			for i, size := range tt.sizes {
+				body := strings.Repeat("x", size)
+				migr := &Migration{Version: uint64(i + 1), TargetVersion: int64(i + 1), FileType: "sql", BufferedBody: strings.NewReader(body)}
+				if err := c.next(migr, &applied); err != nil {
+					t.Fatal(err)
+				}
+				drv.queries = append(drv.queries, body, "insert version")
+			}
+			if err := c.flush(&applied); err != nil {
+				t.Fatal(err)
+			}
+			if len(drv.sent) != len(tt.expected) {
+				t.Fatalf("expected %d chunks, got %d", len(tt.expected), len(drv.sent))
+			}
+			for i, queries := range drv.sent {
+				// a body and the version bookkeeping for each migration
+				if len(queries) != 2*tt.expected[i] {
+					t.Errorf("expected %d migrations in chunk %d, got %d", tt.expected[i], i+1, len(queries)/2)
+				}
+			}
+			if len(applied) != len(tt.sizes) {
+				t.Errorf("expected %d applied versions, got %d", len(tt.sizes), len(applied))
+			}
+		})
+	}
+}
+
+func TestChunkerPlan(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	drv := &chunkTestDriver{}
+	m := &Migrate{databaseDrv: drv, Logger: logger, ChunkCount: 2, plan: &Plan{}}
+	c := &chunker{m: m}
+	var applied []uint64
+	for i := 0; i < 3; i++ {
+		migr := &Migration{Version: uint64(i + 1), TargetVersion: int64(i + 1), FileType: "sql", BufferedBody: strings.NewReader("x")}
+		if err := c.next(migr, &applied); err != nil {
+			t.Fatal(err)
+		}
+		m.plan.next(migr, drv.Queries())
+		drv.queries = append(drv.queries, "x", "insert version")
+	}
+	if err := c.flush(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if len(drv.sent) != 0 || len(applied) != 0 {
+		t.Fatalf("expected the plan not to send or apply anything, sent %d chunks", len(drv.sent))
+	}
+	if len(m.plan.requests) != 2 {
+		t.Fatalf("expected 2 bulk requests in the plan, got %d", len(m.plan.requests))
+	}
+	for i, expected := range []int{1, 1, 2} {
+		step := m.plan.Steps[i]
+		if step.Request != expected || len(step.Queries) != 2 {
+			t.Errorf("expected step %d in request %d with 2 queries, got request %d with %d queries", i+1, expected, step.Request, len(step.Queries))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/hasura/graphql-engine/cli/migrate"
+	"github.com/hasura/graphql-engine/cli/migrate/source"
 	"github.com/pkg/errors"
 )
 
@@ -32,6 +34,10 @@ type CreateOptions struct {
 	MetaDown  []byte
 	SQLUp     []byte
 	SQLDown   []byte
+
+	// SingleFile writes the up and down sql as sections of
+	// a single migration.sql file
+	SingleFile bool
 }
 
 func New(version int64, name, directory string) *CreateOptions {
@@ -143,6 +149,11 @@ func (c *CreateOptions) Create() error {
 		}
 	}
 
+	if c.SingleFile && (c.SQLUp != nil || c.SQLDown != nil) {
+		// Create both sections in migration.sql
+		return createFile(filepath.Join(path, "migration.sql"), c.singleFileSQL())
+	}
+
 	if c.SQLUp != nil {
 		// Create SQLUp
 		err = createFile(filepath.Join(path, "up.sql"), c.SQLUp)
@@ -161,6 +172,26 @@ func (c *CreateOptions) Create() error {
 	return nil
 }
 
+// singleFileSQL returns the content of a single file migration
+func (c *CreateOptions) singleFileSQL() []byte {
+	var buf bytes.Buffer
+	for _, section := range []struct {
+		marker string
+		sql    []byte
+	}{
+		{source.SectionMarkerUp, c.SQLUp},
+		{source.SectionMarkerDown, c.SQLDown},
+	} {
+		buf.WriteString(section.marker + "\n")
+		buf.Write(section.sql)
+		if len(section.sql) > 0 && !bytes.HasSuffix(section.sql, []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
 func (c *CreateOptions) Delete() error {
 	files, err := ioutil.ReadDir(c.Directory)
 	if err != nil {
@@ -183,6 +214,93 @@ func (c *CreateOptions) Delete() error {
 	return nil
 }
 
+// Rename renames the migration files of the version to newVersion, keeping
+// their names. It fails if files of newVersion already exist. If renaming
+// one of the files fails, the renamed files are renamed back.
+func (c *CreateOptions) Rename(newVersion int64) error {
+	files, err := ioutil.ReadDir(c.Directory)
+	if err != nil {
+		return err
+	}
+
+	oldPrefix := fmt.Sprintf("%s_", c.Version)
+	newPrefix := fmt.Sprintf("%d_", newVersion)
+	var renames [][2]string
+	for _, fi := range files {
+		if strings.HasPrefix(fi.Name(), newPrefix) {
+			return fmt.Errorf("migration files for version %d already exist", newVersion)
+		}
+		if strings.HasPrefix(fi.Name(), oldPrefix) {
+			renames = append(renames, [2]string{
+				filepath.Join(c.Directory, fi.Name()),
+				filepath.Join(c.Directory, newPrefix+strings.TrimPrefix(fi.Name(), oldPrefix)),
+			})
+		}
+	}
+	if len(renames) == 0 {
+		return fmt.Errorf("no migration files found for version %s", c.Version)
+	}
+
+	for i, rename := range renames {
+		if err := os.Rename(rename[0], rename[1]); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(renames[j][1], renames[j][0])
+			}
+			return err
+		}
+	}
+	c.Version = strconv.FormatInt(newVersion, 10)
+	return nil
+}
+
+// ReplaceCmd replaces the migration files of versions in directory with the
+// migration files in staging. If moving one of the files fails, the moved
+// files are moved back, so that directory is left unchanged.
+func ReplaceCmd(directory, staging string, versions []int64) error {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	stagedFiles, err := ioutil.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	// the replaced files are kept until all files are moved
+	backup, err := ioutil.TempDir(filepath.Dir(directory), ".replaced-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(backup)
+
+	var moves [][2]string
+	for _, fi := range files {
+		for _, version := range versions {
+			if strings.HasPrefix(fi.Name(), fmt.Sprintf("%d_", version)) {
+				moves = append(moves, [2]string{
+					filepath.Join(directory, fi.Name()),
+					filepath.Join(backup, fi.Name()),
+				})
+			}
+		}
+	}
+	for _, fi := range stagedFiles {
+		moves = append(moves, [2]string{
+			filepath.Join(staging, fi.Name()),
+			filepath.Join(directory, fi.Name()),
+		})
+	}
+
+	for i, move := range moves {
+		if err := os.Rename(move[0], move[1]); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(moves[j][1], moves[j][0])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 func createFile(fname string, data []byte) error {
 	file, err := os.Create(fname)
 	if err != nil {
@@ -222,8 +340,8 @@ func DownCmd(m *migrate.Migrate, limit int64) error {
 	}
 }
 
-func SquashCmd(m *migrate.Migrate, from uint64, version int64, name, directory string) (versions []int64, err error) {
-	versions, upMeta, upSql, downMeta, downSql, err := m.Squash(from)
+func SquashCmd(m *migrate.Migrate, from, to uint64, version int64, name, directory string) (versions []int64, err error) {
+	versions, upMeta, upSql, downMeta, downSql, err := m.Squash(from, to)
 	if err != nil {
 		return
 	}
@@ -254,6 +372,25 @@ func SquashCmd(m *migrate.Migrate, from uint64, version int64, name, directory s
 	return
 }
 
+// SquashSQLCmd squashes the sql migrations from version from up to and
+// including version to into a new migration in directory
+func SquashSQLCmd(m *migrate.Migrate, from, to uint64, version int64, name, directory string) (versions []int64, err error) {
+	versions, upSql, downSql, err := m.SquashSQL(from, to)
+	if err != nil {
+		return
+	}
+
+	createOptions := New(version, name, directory)
+	createOptions.SQLUp = upSql
+	createOptions.SQLDown = downSql
+
+	err = createOptions.Create()
+	if err != nil {
+		return versions, errors.Wrap(err, "cannot create migration")
+	}
+	return
+}
+
 func GotoVersionCmd(m *migrate.Migrate, gotoVersion int64) error {
 	return m.GotoVersion(gotoVersion)
 }
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// longName is a file name which cannot be renamed to a longer version,
+// because the new name exceeds the file name limit
+var longName = "b" + strings.Repeat("x", 250)
+
+func createFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	var names []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	createFiles(t, dir, "1_init/up.sql", "1_init/down.sql", "2_posts/up.sql")
+
+	c := CreateOptions{Version: "1", Directory: dir}
+	if err := c.Rename(3); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"2_posts/up.sql", "3_init/down.sql", "3_init/up.sql"}
+	if files := listFiles(t, dir); !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+	if c.Version != "3" {
+		t.Errorf("expected version 3, got %s", c.Version)
+	}
+
+	c = CreateOptions{Version: "2", Directory: dir}
+	if err := c.Rename(3); err == nil {
+		t.Error("expected an error when files of the new version exist")
+	}
+}
+
+func TestRenameRollsBack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// 1_a is renamed first, 1_bxxx... fails
+	createFiles(t, dir, "1_a.up.sql", "1_"+longName)
+
+	c := CreateOptions{Version: "1", Directory: dir}
+	if err := c.Rename(1000000000000); err == nil {
+		t.Fatal("expected renaming to fail")
+	}
+	expected := []string{"1_a.up.sql", "1_" + longName}
+	if files := listFiles(t, dir); !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+	if c.Version != "1" {
+		t.Errorf("expected version 1, got %s", c.Version)
+	}
+}
+
+func TestReplaceCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		staged   []string
+		wantErr  bool
+		expected []string
+	}{
+		{
+			"replaces the versions",
+			[]string{"1_a/up.sql", "2_b/up.sql", "3_c/up.sql"},
+			[]string{"2_squashed/up.sql"},
+			false,
+			[]string{"2_squashed/up.sql", "3_c/up.sql"},
+		},
+		{
+			"rolls back when a file cannot be moved",
+			// 3_c is not replaced, so the staged 3_c cannot be moved over it
+			[]string{"1_a/up.sql", "2_b/up.sql", "3_c/up.sql"},
+			[]string{"2_squashed/up.sql", "3_c/up.sql"},
+			true,
+			[]string{"1_a/up.sql", "2_b/up.sql", "3_c/up.sql"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "replace")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			dir := filepath.Join(root, "migrations")
+			staging := filepath.Join(root, "staging")
+			createFiles(t, dir, tt.existing...)
+			createFiles(t, staging, tt.staged...)
+
+			err = ReplaceCmd(dir, staging, []int64{1, 2})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got %v", tt.wantErr, err)
+			}
+			if files := listFiles(t, dir); !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("expected files %v, got %v", tt.expected, files)
+			}
+			// the backup of the replaced files is removed
+			entries, err := ioutil.ReadDir(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".replaced-") {
+					t.Errorf("expected the backup %s to be removed", e.Name())
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,28 @@ var (
 	ErrLocked = fmt.Errorf("can't acquire lock")
 )
 
+// ErrLockHeld is returned by Lock when another process holds the lock
+// and it was not released within the lock timeout.
+type ErrLockHeld struct {
+	// Holder identifies the process holding the lock.
+	Holder string
+	// Since is the time at which the lock was acquired by Holder.
+	Since string
+}
+
+func (e ErrLockHeld) Error() string {
+	msg := fmt.Sprintf("%v: migrations are locked by %s", ErrLocked, e.Holder)
+	if e.Since != "" {
+		msg = fmt.Sprintf("%s since %s", msg, e.Since)
+	}
+	return msg + ", if that process is no longer running release the lock with hasura migrate unlock"
+}
+
+// Cause returns ErrLocked, so that errors.Cause can be used to check for it.
+func (e ErrLockHeld) Cause() error {
+	return ErrLocked
+}
+
 const NilVersion int64 = -1
 
 var driversMu sync.RWMutex
@@ -55,34 +77,77 @@ type Driver interface {
 	// Lock should acquire a database lock so that only one migration process
 	// can run at a time. Migrate will call this function before Run is called.
 	// If the implementation can't provide this functionality, return nil.
-	// Return database.ErrLocked if database is already locked.
+	// Return database.ErrLocked if database is already locked, or
+	// database.ErrLockHeld if the lock is held by another process.
 	Lock() error
 
 	// Unlock should release the lock. Migrate will call this function after
 	// all migrations have been run.
 	UnLock() error
 
+	// ForceUnLock releases the lock held by any process, e.g. one which was
+	// killed while holding it. It returns the holder of the released lock,
+	// none if the lock was not held.
+	ForceUnLock() (holder string, err error)
+
+	// Flush sends the migrations run so far to the database without
+	// releasing the lock. Migrate calls this function after each version
+	// when migrations are applied one by one.
+	Flush() error
+
 	// Run applies a migration to the database. migration is garantueed to be not nil.
-	Run(migration io.Reader, fileType, fileName string) error
+	// version is the version the migration belongs to, NilVersion if none.
+	Run(migration io.Reader, fileType, fileName string, version int64) error
 
 	// Reset Migration Query Args
 	ResetQuery()
 
+	// Queries returns the queries added since the last Flush, which are
+	// yet to be sent to the database.
+	Queries() []interface{}
+
 	// InsertVersion saves version
 	// Migrate will call this function before and after each call to Run.
 	// version must be >= -1. -1 means NilVersion.
-	InsertVersion(version int64) error
+	// kind is recorded in the history, see KindApply.
+	InsertVersion(version int64, kind string) error
 
 	// SetVersion saves version and dirty state.
 	// Migrate will call this function before and after each call to Run.
 	// version must be >= -1. -1 means NilVersion.
-	RemoveVersion(version int64) error
+	// kind is recorded in the history, see KindApply.
+	RemoveVersion(version int64, kind string) error
+
+	// SetDirty marks version as dirty. InsertVersion clears the dirty
+	// state and RemoveVersion removes the version along with it.
+	// Migrate will call this function before running a version
+	// when migrations are applied one by one.
+	SetDirty(version int64) error
+
+	// SetChecksum saves the checksum of the up migration files of version.
+	// Migrate will call this function after InsertVersion.
+	SetChecksum(version int64, checksum string) error
+
+	// Checksum returns the checksum saved for version, if any
+	Checksum(version uint64) (checksum string, ok bool)
+
+	// SetRepeatableChecksum saves the checksum of the repeatable
+	// migration with the given name once it is applied.
+	SetRepeatableChecksum(name string, checksum string) error
+
+	// RepeatableChecksums returns the checksums of the repeatable
+	// migrations applied on the database, by name
+	RepeatableChecksums() map[string]string
 
 	// Version returns the currently active version and if the database is dirty.
 	// When no migration has been applied, it must return version -1.
-	// Dirty means, a previous migration failed and user interaction is required.
+	// Dirty means, a previous migration failed and user interaction is required,
+	// in which case the dirty version is returned.
 	Version() (version int64, dirty bool, err error)
 
+	// IsDirty returns true if version is marked as dirty in the database
+	IsDirty(version uint64) bool
+
 	// First returns the very first migration version available to the driver.
 	// Migrate will call this function multiple times
 	First() (version uint64, ok bool)
@@ -115,6 +180,21 @@ type Driver interface {
 	SchemaDriver
 
 	SeedDriver
+
+	HistoryDriver
+
+	// MoveState moves the versions, settings, history and repeatable
+	// migrations from the tables at the location from to the ones used
+	// by the driver.
+	MoveState(from StateLocation) error
+}
+
+// StateLocation is where a driver keeps the migrations state.
+// Empty fields mean the defaults of the driver.
+type StateLocation struct {
+	Schema          string
+	MigrationsTable string
+	SettingsTable   string
 }
 
 // Open returns a new driver instance.
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hasura/graphql-engine/cli/metadata/types"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
@@ -32,11 +33,19 @@ func (m *mockDriver) UnLock() error {
 	return nil
 }
 
+func (m *mockDriver) ForceUnLock() (string, error) {
+	return "", nil
+}
+
+func (m *mockDriver) Flush() error {
+	return nil
+}
+
 func (m *mockDriver) Scan() error {
 	return nil
 }
 
-func (m *mockDriver) Run(migration io.Reader, fileType, fileName string) error {
+func (m *mockDriver) Run(migration io.Reader, fileType, fileName string, version int64) error {
 	return nil
 }
 
@@ -48,15 +57,47 @@ func (m *mockDriver) Version() (version int64, dirty bool, err error) {
 	return 0, false, nil
 }
 
+func (m *mockDriver) SetDirty(version int64) error {
+	return nil
+}
+
+func (m *mockDriver) IsDirty(version uint64) bool {
+	return false
+}
+
+func (m *mockDriver) SetChecksum(version int64, checksum string) error {
+	return nil
+}
+
+func (m *mockDriver) Checksum(version uint64) (checksum string, ok bool) {
+	return "", false
+}
+
+func (m *mockDriver) GetHistory() ([]HistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *mockDriver) MoveState(from StateLocation) error {
+	return nil
+}
+
+func (m *mockDriver) SetRepeatableChecksum(name string, checksum string) error {
+	return nil
+}
+
+func (m *mockDriver) RepeatableChecksums() map[string]string {
+	return nil
+}
+
 func (m *mockDriver) Drop() error {
 	return nil
 }
 
-func (m *mockDriver) InsertVersion(version int64) error {
+func (m *mockDriver) InsertVersion(version int64, kind string) error {
 	return nil
 }
 
-func (m *mockDriver) RemoveVersion(version int64) error {
+func (m *mockDriver) RemoveVersion(version int64, kind string) error {
 	return nil
 }
 
@@ -88,6 +129,10 @@ func (m *mockDriver) Query(data interface{}) error {
 	return nil
 }
 
+func (m *mockDriver) Queries() []interface{} {
+	return nil
+}
+
 func (m *mockDriver) ResetQuery() {
 	return
 }
@@ -103,6 +148,9 @@ func (m *mockDriver) SetMetadataPlugins(plugins types.MetadataPlugins) {
 func (m *mockDriver) EnableCheckMetadataConsistency(enabled bool) {
 }
 
+func (m *mockDriver) EnableIncrementalMetadata(enabled bool) {
+}
+
 func (m *mockDriver) GetInconsistentMetadata() (bool, []InconsistentMetadataInterface, error) {
 	return false, []InconsistentMetadataInterface{}, nil
 }
@@ -212,3 +260,17 @@ func TestOpen(t *testing.T) {
 		})
 	}
 }
+
+func TestErrLockHeld(t *testing.T) {
+	err := ErrLockHeld{
+		Holder: "ci@runner-1 (pid 42)",
+		Since:  "2020-09-01 10:00:00+00",
+	}
+	want := "can't acquire lock: migrations are locked by ci@runner-1 (pid 42) since 2020-09-01 10:00:00+00, if that process is no longer running release the lock with hasura migrate unlock"
+	if err.Error() != want {
+		t.Fatalf("expected %q got %q", want, err.Error())
+	}
+	if errors.Cause(err) != ErrLocked {
+		t.Fatalf("expected cause to be ErrLocked got %v", errors.Cause(err))
+	}
+}
@@ -12,10 +12,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	yaml "github.com/ghodss/yaml"
 	"github.com/hasura/graphql-engine/cli/metadata/types"
 	"github.com/hasura/graphql-engine/cli/migrate/database"
+	"github.com/hasura/graphql-engine/cli/migrate/source"
 	"github.com/oliveagle/jsonpath"
 	"github.com/parnurzeal/gorequest"
 	log "github.com/sirupsen/logrus"
@@ -39,8 +41,16 @@ var (
 )
 
 type Config struct {
+	Schema                         string
 	MigrationsTable                string
 	SettingsTable                  string
+	LockTable                      string
+	LockTimeout                    time.Duration
+	lockHolder                     string
+	HistoryTable                   string
+	RepeatableTable                string
+	Actor                          string
+	CLIVersion                     string
 	queryURL                       *nurl.URL
 	graphqlURL                     *nurl.URL
 	pgDumpURL                      *nurl.URL
@@ -48,6 +58,7 @@ type Config struct {
 	isCMD                          bool
 	Plugins                        types.MetadataPlugins
 	enableCheckMetadataConsistency bool
+	incrementalMetadata            bool
 	Req                            *gorequest.SuperAgent
 }
 
@@ -59,6 +70,13 @@ type HasuraDB struct {
 	jsonPath       map[string]string
 	isLocked       bool
 	logger         *log.Logger
+
+	// versions holds the version each query of migrationQuery belongs to,
+	// by index like jsonPath
+	versions map[string]int64
+	// firstLines holds the line of its file the sql of a run_sql query of
+	// migrationQuery starts on, by index like jsonPath
+	firstLines map[string]int
 }
 
 func WithInstance(config *Config, logger *log.Logger) (database.Driver, error) {
@@ -67,6 +85,34 @@ func WithInstance(config *Config, logger *log.Logger) (database.Driver, error) {
 		return nil, ErrNilConfig
 	}
 
+	if config.Schema == "" {
+		config.Schema = DefaultSchema
+	}
+	if config.MigrationsTable == "" {
+		config.MigrationsTable = DefaultMigrationsTable
+	}
+	if config.SettingsTable == "" {
+		config.SettingsTable = DefaultSettingsTable
+	}
+	if config.LockTable == "" {
+		config.LockTable = lockTableFor(config.MigrationsTable)
+	}
+	if config.LockTimeout == 0 {
+		config.LockTimeout = DefaultLockTimeout
+	}
+	if config.lockHolder == "" {
+		config.lockHolder = newLockHolder()
+	}
+	if config.HistoryTable == "" {
+		config.HistoryTable = config.MigrationsTable + "_history"
+	}
+	if config.RepeatableTable == "" {
+		config.RepeatableTable = config.MigrationsTable + "_repeatable"
+	}
+	if config.Actor == "" {
+		config.Actor = osUsername()
+	}
+
 	hx := &HasuraDB{
 		config:     config,
 		migrations: database.NewMigrations(),
@@ -74,6 +120,11 @@ func WithInstance(config *Config, logger *log.Logger) (database.Driver, error) {
 		logger:     logger,
 	}
 
+	if err := hx.ensureSchema(); err != nil {
+		logger.Debug(err)
+		return nil, err
+	}
+
 	if err := hx.ensureVersionTable(); err != nil {
 		logger.Debug(err)
 		return nil, err
@@ -83,6 +134,21 @@ func WithInstance(config *Config, logger *log.Logger) (database.Driver, error) {
 		logger.Debug(err)
 		return nil, err
 	}
+
+	if err := hx.ensureLockTable(); err != nil {
+		logger.Debug(err)
+		return nil, err
+	}
+
+	if err := hx.ensureHistoryTable(); err != nil {
+		logger.Debug(err)
+		return nil, err
+	}
+
+	if err := hx.ensureRepeatableTable(); err != nil {
+		logger.Debug(err)
+		return nil, err
+	}
 	return hx, nil
 }
 
@@ -115,14 +181,27 @@ func (h *HasuraDB) Open(url string, isCMD bool, tlsConfig *tls.Config, logger *l
 		}
 	}
 
+	lockTimeout := DefaultLockTimeout
+	if t := params.Get("lock_timeout"); t != "" {
+		lockTimeout, err = time.ParseDuration(t)
+		if err != nil {
+			logger.Debug(err)
+			return nil, fmt.Errorf("invalid lock_timeout %q: %v", t, err)
+		}
+	}
+
 	req := gorequest.New()
 	if tlsConfig != nil {
 		req.TLSClientConfig(tlsConfig)
 	}
 
 	config := &Config{
-		MigrationsTable: DefaultMigrationsTable,
-		SettingsTable:   DefaultSettingsTable,
+		Schema:          params.Get("schema"),
+		MigrationsTable: params.Get("migrations_table"),
+		SettingsTable:   params.Get("settings_table"),
+		LockTimeout:     lockTimeout,
+		Actor:           params.Get("actor"),
+		CLIVersion:      params.Get("cli_version"),
 		queryURL: &nurl.URL{
 			Scheme: scheme,
 			Host:   hurl.Host,
@@ -158,7 +237,10 @@ func (h *HasuraDB) Close() error {
 
 func (h *HasuraDB) Scan() error {
 	h.migrations = database.NewMigrations()
-	return h.getVersions()
+	if err := h.getVersions(); err != nil {
+		return err
+	}
+	return h.getRepeatables()
 }
 
 func (h *HasuraDB) Lock() error {
@@ -166,11 +248,19 @@ func (h *HasuraDB) Lock() error {
 		return database.ErrLocked
 	}
 
+	// take the lock on the server so that other processes cannot
+	// apply migrations at the same time
+	if err := h.acquireLock(); err != nil {
+		return err
+	}
+
 	h.migrationQuery = HasuraInterfaceBulk{
 		Type: "bulk",
 		Args: make([]interface{}, 0),
 	}
 	h.jsonPath = make(map[string]string)
+	h.firstLines = make(map[string]int)
+	h.versions = make(map[string]int64)
 	h.isLocked = true
 	return nil
 }
@@ -181,9 +271,33 @@ func (h *HasuraDB) UnLock() error {
 	}
 
 	defer func() {
+		if err := h.releaseLock(); err != nil {
+			h.logger.Warnf("releasing migrations lock failed: %v", err)
+		}
 		h.isLocked = false
 	}()
 
+	return h.sendMigrationQuery()
+}
+
+// Flush sends the queries added so far and starts a new bulk query,
+// the lock is held until UnLock is called.
+func (h *HasuraDB) Flush() error {
+	if !h.isLocked {
+		return nil
+	}
+
+	defer func() {
+		h.migrationQuery.ResetArgs()
+		h.jsonPath = make(map[string]string)
+		h.firstLines = make(map[string]int)
+		h.versions = make(map[string]int64)
+	}()
+
+	return h.sendMigrationQuery()
+}
+
+func (h *HasuraDB) sendMigrationQuery() error {
 	if len(h.migrationQuery.Args) == 0 {
 		return nil
 	}
@@ -225,6 +339,13 @@ func (h *HasuraDB) UnLock() error {
 					if ok {
 						herror.migrationFile = migrationNumber
 					}
+					herror.location = h.errorLocation(result[0][1], herror)
+					// the whole bulk query is rolled back, so the version is
+					// only reported. When applying per migration, Migrate marks
+					// the version dirty before sending its queries.
+					if version, ok := h.versions[result[0][1]]; ok {
+						herror.version = version
+					}
 				}
 			}
 			return herror
@@ -235,7 +356,27 @@ func (h *HasuraDB) UnLock() error {
 	return nil
 }
 
-func (h *HasuraDB) Run(migration io.Reader, fileType, fileName string) error {
+// errorLocation returns the location of the error in the sql of the
+// run_sql query at index of the migration query, if it can be located
+func (h *HasuraDB) errorLocation(index string, herror HasuraError) *sqlLocation {
+	i, err := strconv.Atoi(index)
+	if err != nil || i >= len(h.migrationQuery.Args) {
+		return nil
+	}
+	query, ok := h.migrationQuery.Args[i].(HasuraInterfaceQuery)
+	if !ok {
+		return nil
+	}
+	sqlInput, ok := query.Args.(RunSQLInput)
+	if !ok {
+		return nil
+	}
+	return newSQLLocation(sqlInput.SQL, h.firstLines[index], herror.Internal)
+}
+
+func (h *HasuraDB) Run(migration io.Reader, fileType, fileName string, version int64) error {
+	// a section of a single file migration does not start on the first line
+	firstLine := source.Line(migration)
 	migr, err := ioutil.ReadAll(migration)
 	if err != nil {
 		return err
@@ -258,6 +399,8 @@ func (h *HasuraDB) Run(migration io.Reader, fileType, fileName string) error {
 		}
 		h.migrationQuery.Args = append(h.migrationQuery.Args, t)
 		h.jsonPath[fmt.Sprintf("%d", len(h.migrationQuery.Args)-1)] = fileName
+		h.firstLines[fmt.Sprintf("%d", len(h.migrationQuery.Args)-1)] = firstLine
+		h.belongsTo(version)
 	case "meta":
 		var t []interface{}
 		err := yaml.Unmarshal(migr, &t)
@@ -269,6 +412,7 @@ func (h *HasuraDB) Run(migration io.Reader, fileType, fileName string) error {
 		for _, v := range t {
 			h.migrationQuery.Args = append(h.migrationQuery.Args, v)
 			h.jsonPath[fmt.Sprintf("%d", len(h.migrationQuery.Args)-1)] = fileName
+			h.belongsTo(version)
 		}
 	}
 	return nil
@@ -276,27 +420,66 @@ func (h *HasuraDB) Run(migration io.Reader, fileType, fileName string) error {
 
 func (h *HasuraDB) ResetQuery() {
 	h.migrationQuery.ResetArgs()
+	h.versions = make(map[string]int64)
+}
+
+func (h *HasuraDB) Queries() []interface{} {
+	return h.migrationQuery.Args
+}
+
+func (h *HasuraDB) InsertVersion(version int64, kind string) error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: h.recordVersionSQL(version, "up", kind, `INSERT INTO `+fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable)+` (version, dirty, applied_at, applied_by, cli_version, duration_ms) SELECT `+strconv.FormatInt(version, 10)+`, false, applied_at, applied_by, cli_version, duration_ms FROM r ON CONFLICT (version) DO UPDATE SET dirty = false, applied_at = excluded.applied_at, applied_by = excluded.applied_by, cli_version = excluded.cli_version, duration_ms = excluded.duration_ms`),
+		},
+	}
+	h.migrationQuery.Args = append(h.migrationQuery.Args, query)
+	h.belongsTo(version)
+	return nil
+}
+
+func (h *HasuraDB) RemoveVersion(version int64, kind string) error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: h.recordVersionSQL(version, "down", kind, `DELETE FROM `+fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable)+` WHERE version = `+strconv.FormatInt(version, 10)),
+		},
+	}
+	h.migrationQuery.Args = append(h.migrationQuery.Args, query)
+	h.belongsTo(version)
+	return nil
 }
 
-func (h *HasuraDB) InsertVersion(version int64) error {
+func (h *HasuraDB) SetDirty(version int64) error {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.MigrationsTable) + ` (version, dirty) VALUES (` + strconv.FormatInt(version, 10) + `, ` + fmt.Sprintf("%t", false) + `)`,
+			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable) + ` (version, dirty) VALUES (` + strconv.FormatInt(version, 10) + `, ` + fmt.Sprintf("%t", true) + `) ON CONFLICT (version) DO UPDATE SET dirty = true`,
 		},
 	}
 	h.migrationQuery.Args = append(h.migrationQuery.Args, query)
+	h.belongsTo(version)
 	return nil
 }
 
-func (h *HasuraDB) RemoveVersion(version int64) error {
+// belongsTo notes that the last query of the migration query is part of version
+func (h *HasuraDB) belongsTo(version int64) {
+	if version == database.NilVersion {
+		return
+	}
+	h.versions[fmt.Sprintf("%d", len(h.migrationQuery.Args)-1)] = version
+}
+
+func (h *HasuraDB) SetChecksum(version int64, checksum string) error {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `DELETE FROM ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.MigrationsTable) + ` WHERE version = ` + strconv.FormatInt(version, 10),
+			SQL: `UPDATE ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable) + ` SET checksum = ` + quoteLiteral(checksum) + ` WHERE version = ` + strconv.FormatInt(version, 10),
 		},
 	}
 	h.migrationQuery.Args = append(h.migrationQuery.Args, query)
+	h.belongsTo(version)
 	return nil
 }
 
@@ -305,7 +488,7 @@ func (h *HasuraDB) getVersions() (err error) {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `SELECT version, dirty FROM ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.MigrationsTable),
+			SQL: `SELECT version, dirty, coalesce(checksum, '') FROM ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable),
 		},
 	}
 
@@ -345,12 +528,24 @@ func (h *HasuraDB) getVersions() (err error) {
 		}
 
 		h.migrations.Append(version)
+		// postgres booleans are returned as t or f
+		if dirty, err := strconv.ParseBool(val[1]); err == nil && dirty {
+			h.migrations.SetDirty(version)
+		}
+		// versions applied before checksums were recorded have none
+		if val[2] != "" {
+			h.migrations.SetChecksum(version, val[2])
+		}
 	}
 
 	return nil
 }
 
 func (h *HasuraDB) Version() (version int64, dirty bool, err error) {
+	if dirtyVersion, ok := h.migrations.FirstDirty(); ok {
+		return int64(dirtyVersion), true, nil
+	}
+
 	tmpVersion, ok := h.migrations.Last()
 	if !ok {
 		return database.NilVersion, false, nil
@@ -359,16 +554,46 @@ func (h *HasuraDB) Version() (version int64, dirty bool, err error) {
 	return int64(tmpVersion), false, nil
 }
 
+func (h *HasuraDB) IsDirty(version uint64) bool {
+	return h.migrations.IsDirty(version)
+}
+
+func (h *HasuraDB) Checksum(version uint64) (checksum string, ok bool) {
+	return h.migrations.Checksum(version)
+}
+
 func (h *HasuraDB) Drop() error {
 	return nil
 }
 
+// ensureSchema creates the schema holding the migrations state, if it is not
+// the default one
+func (h *HasuraDB) ensureSchema() error {
+	if h.config.Schema == DefaultSchema {
+		return nil
+	}
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `CREATE SCHEMA IF NOT EXISTS ` + h.config.Schema,
+		},
+	}
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
+	return nil
+}
+
 func (h *HasuraDB) ensureVersionTable() error {
 	// check if migration table exists
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `SELECT COUNT(1) FROM information_schema.tables WHERE table_name = '` + h.config.MigrationsTable + `' AND table_schema = '` + DefaultSchema + `' LIMIT 1`,
+			SQL: `SELECT COUNT(1) FROM information_schema.tables WHERE table_name = '` + h.config.MigrationsTable + `' AND table_schema = '` + h.config.Schema + `' LIMIT 1`,
 		},
 	}
 
@@ -395,14 +620,14 @@ func (h *HasuraDB) ensureVersionTable() error {
 	}
 
 	if hres.Result[1][0] != "0" {
-		return nil
+		return h.upgradeVersionTable()
 	}
 
 	// Now Create the table
 	query = HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `CREATE TABLE ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.MigrationsTable) + ` (version bigint not null primary key, dirty boolean not null)`,
+			SQL: `CREATE TABLE ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable) + ` (version bigint not null primary key, dirty boolean not null)`,
 		},
 	}
 
@@ -424,9 +649,79 @@ func (h *HasuraDB) ensureVersionTable() error {
 		return fmt.Errorf("Creating Version table failed %s", hres.ResultType)
 	}
 
+	return h.upgradeVersionTable()
+}
+
+// versionTableColumns are the columns added to the version table after it
+// was first created, along with their definitions
+var versionTableColumns = []struct {
+	name       string
+	definition string
+}{
+	{"checksum", "text"},
+	{"applied_at", "timestamptz"},
+	{"applied_by", "text"},
+	{"cli_version", "text"},
+	{"duration_ms", "bigint"},
+}
+
+// upgradeVersionTable adds the columns missing in a version table created by
+// an older version of the cli
+func (h *HasuraDB) upgradeVersionTable() error {
+	existing, err := h.tableColumns(h.config.Schema, h.config.MigrationsTable)
+	if err != nil {
+		return err
+	}
+
+	var alters []string
+	for _, column := range versionTableColumns {
+		if !existing[column.name] {
+			alters = append(alters, fmt.Sprintf("ADD COLUMN %s %s", column.name, column.definition))
+		}
+	}
+	if len(alters) == 0 {
+		return nil
+	}
+
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `ALTER TABLE ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.MigrationsTable) + ` ` + strings.Join(alters, ", "),
+		},
+	}
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
 	return nil
 }
 
+// tableColumns returns the columns of a table, which are none if
+// the table does not exist
+func (h *HasuraDB) tableColumns(schema, table string) (map[string]bool, error) {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `SELECT column_name FROM information_schema.columns WHERE table_name = ` + quoteLiteral(table) + ` AND table_schema = ` + quoteLiteral(schema),
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool)
+	for index, val := range hres.Result {
+		if index == 0 {
+			continue
+		}
+		columns[val[0]] = true
+	}
+	return columns, nil
+}
+
 func (h *HasuraDB) sendv1Query(m interface{}) (resp *http.Response, body []byte, err error) {
 	request := h.config.Req.Clone()
 	request = request.Post(h.config.queryURL.String()).Send(m)
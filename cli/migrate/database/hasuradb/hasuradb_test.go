@@ -0,0 +1,79 @@
+package hasuradb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	nurl "net/url"
+	"strings"
+	"testing"
+
+	"github.com/parnurzeal/gorequest"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestSendMigrationQueryReportsFailedVersion(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		w.WriteHeader(http.StatusBadRequest)
+		// the second query of the bulk, the metadata of version 1
+		w.Write([]byte(`{"path": "$.args[1].args", "error": "table \"users\" does not exist", "code": "not-exists"}`))
+	}))
+	defer server.Close()
+	queryURL, err := nurl.Parse(server.URL + "/v1/query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, _ := test.NewNullLogger()
+	h := &HasuraDB{
+		config: &Config{
+			Schema:          DefaultSchema,
+			MigrationsTable: DefaultMigrationsTable,
+			HistoryTable:    DefaultMigrationsTable + "_history",
+			queryURL:        queryURL,
+			isCMD:           true,
+			Req:             gorequest.New(),
+		},
+		migrationQuery: HasuraInterfaceBulk{Type: "bulk", Args: make([]interface{}, 0)},
+		jsonPath:       make(map[string]string),
+		firstLines:     make(map[string]int),
+		versions:       make(map[string]int64),
+		logger:         logger,
+	}
+
+	// queued like a v1 project: up.sql and up.yaml of each version, then its version
+	if err := h.Run(strings.NewReader("CREATE TABLE users (id serial primary key);"), "sql", "1_init.up.sql", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Run(strings.NewReader("- type: track_table\n  args: {name: users}\n"), "meta", "1_init.up.yaml", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.InsertVersion(1, "apply"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Run(strings.NewReader("CREATE TABLE posts (id serial primary key);"), "sql", "2_posts.up.sql", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.InsertVersion(2, "apply"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = h.sendMigrationQuery()
+	herror, ok := errors.Cause(err).(HasuraError)
+	if !ok {
+		t.Fatalf("expected a HasuraError, got %v", err)
+	}
+	if herror.version != 1 {
+		t.Errorf("expected version 1 to be reported, got %d", herror.version)
+	}
+	if herror.migrationFile != "1_init.up.yaml" {
+		t.Errorf("expected file 1_init.up.yaml to be reported, got %s", herror.migrationFile)
+	}
+	// the bulk query is rolled back, nothing is marked dirty
+	if len(requests) != 1 {
+		t.Errorf("expected only the bulk query to be sent, got %d requests", len(requests))
+	}
+}
@@ -0,0 +1,114 @@
+package hasuradb
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+)
+
+const (
+	// versionRecordedAtSetting holds the time at which the last version was
+	// recorded in the current transaction. It is used to compute the
+	// execution time of each version when many are applied in a single bulk.
+	versionRecordedAtSetting = "hasura_cli.version_recorded_at"
+)
+
+// ensureHistoryTable creates the table which records every version
+// applied or rolled back on the database, and adds the kind column to a
+// table created by an older version of the cli
+func (h *HasuraDB) ensureHistoryTable() error {
+	table := fmt.Sprintf("%s.%s", h.config.Schema, h.config.HistoryTable)
+	existing, err := h.tableColumns(h.config.Schema, h.config.HistoryTable)
+	if err != nil {
+		return err
+	}
+	var sql string
+	switch {
+	case len(existing) == 0:
+		sql = `CREATE TABLE IF NOT EXISTS ` + table + ` (id bigserial primary key, version bigint not null, direction text not null, kind text, applied_at timestamptz not null, applied_by text, cli_version text, duration_ms bigint)`
+	case !existing["kind"]:
+		sql = `ALTER TABLE ` + table + ` ADD COLUMN kind text`
+	default:
+		return nil
+	}
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: sql,
+		},
+	}
+
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		h.logger.Debug(err)
+		return err
+	}
+	h.logger.Debug("response: ", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
+	return nil
+}
+
+// recordVersionSQL returns the SQL which applies change to the version table
+// and records it in the history table with kind. change is a data modifying
+// statement which can select applied_at, applied_by, cli_version and
+// duration_ms from r. The duration is only known for versions which are run.
+func (h *HasuraDB) recordVersionSQL(version int64, direction, kind, change string) string {
+	duration := `NULL::bigint`
+	if kind == database.KindApply {
+		duration = `(extract(epoch FROM clock_timestamp() - coalesce(nullif(current_setting('` + versionRecordedAtSetting + `', true), '')::timestamptz, transaction_timestamp())) * 1000)::bigint`
+	}
+	return `WITH r AS (SELECT clock_timestamp() AS applied_at, ` + quoteLiteral(h.config.Actor) + `::text AS applied_by, ` + quoteLiteral(h.config.CLIVersion) + `::text AS cli_version, ` +
+		duration + ` AS duration_ms), ` +
+		`v AS (` + change + `) ` +
+		`INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.HistoryTable) + ` (version, direction, kind, applied_at, applied_by, cli_version, duration_ms) SELECT ` + strconv.FormatInt(version, 10) + `, ` + quoteLiteral(direction) + `, ` + quoteLiteral(kind) + `, applied_at, applied_by, cli_version, duration_ms FROM r; ` +
+		`SELECT set_config('` + versionRecordedAtSetting + `', clock_timestamp()::text, true)`
+}
+
+// GetHistory returns the versions applied or rolled back on the database,
+// oldest first
+func (h *HasuraDB) GetHistory() ([]database.HistoryEntry, error) {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `SELECT version, direction, coalesce(kind, ''), to_char(applied_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'), coalesce(applied_by, ''), coalesce(cli_version, ''), coalesce(duration_ms, -1) FROM ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.HistoryTable) + ` ORDER BY id`,
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]database.HistoryEntry, 0, len(hres.Result))
+	for index, val := range hres.Result {
+		if index == 0 {
+			continue
+		}
+		version, err := strconv.ParseUint(val[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		appliedAt, err := time.Parse(time.RFC3339Nano, val[3])
+		if err != nil {
+			return nil, err
+		}
+		entry := database.HistoryEntry{
+			Version:    version,
+			Direction:  val[1],
+			Kind:       val[2],
+			AppliedAt:  appliedAt,
+			AppliedBy:  val[4],
+			CLIVersion: val[5],
+		}
+		if durationMs, err := strconv.ParseInt(val[6], 10, 64); err == nil && durationMs >= 0 {
+			entry.Duration = time.Duration(durationMs) * time.Millisecond
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
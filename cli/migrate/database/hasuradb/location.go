@@ -0,0 +1,185 @@
+package hasuradb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/migrate/sqlparse"
+)
+
+// snippetContext is the number of lines of the failing statement shown
+// before and after the line of the error
+const snippetContext = 3
+
+var quotedNameRegex = regexp.MustCompile(`"([^"]+)"`)
+
+// sqlLocation is the location of an error in the sql of a migration file
+type sqlLocation struct {
+	// line and column are 1-based, relative to the migration file
+	line   int
+	column int
+	// approximate is set if postgres reported no position, and the
+	// location is the one of a name quoted in the error message
+	approximate bool
+	// snippet shows the lines of the failing statement around the error
+	snippet string
+}
+
+func (l *sqlLocation) String() string {
+	if l.approximate {
+		return fmt.Sprintf("near line %d, column %d", l.line, l.column)
+	}
+	return fmt.Sprintf("line %d, column %d", l.line, l.column)
+}
+
+// newSQLLocation returns the location of the error of a run_sql query with
+// the sql, which starts on firstLine of its file. The position of the error
+// is taken from the postgres error in internal, or from a name quoted in
+// the error message which occurs only once in the sql, in which case the
+// location is approximate. Returns nil if the error cannot be located.
+func newSQLLocation(sql string, firstLine int, internal interface{}) *sqlLocation {
+	pgError := postgresErrorFields(internal)
+	if pgError == nil {
+		return nil
+	}
+	if firstLine < 1 {
+		firstLine = 1
+	}
+	runes := []rune(sql)
+	l := &sqlLocation{line: 1, column: 1}
+	position, ok := reportedPosition(pgError)
+	if !ok {
+		position, ok = namePosition(sql, pgError)
+		l.approximate = true
+	}
+	// the position is 1-based
+	if !ok || position < 1 || position > len(runes) {
+		return nil
+	}
+	offset := position - 1
+
+	for _, c := range runes[:offset] {
+		if c == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+	l.snippet = statementSnippet(sql, offset, l.line, l.column, firstLine)
+	l.line += firstLine - 1
+	return l
+}
+
+// postgresErrorFields returns the fields of the postgres error in the
+// internal error of a failed run_sql query
+func postgresErrorFields(internal interface{}) map[string]interface{} {
+	v, ok := internal.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	pgError, ok := v["error"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return pgError
+}
+
+// reportedPosition returns the character position of the error reported by postgres
+func reportedPosition(pgError map[string]interface{}) (int, bool) {
+	switch position := pgError["position"].(type) {
+	case float64:
+		return int(position), true
+	case string:
+		p, err := strconv.Atoi(position)
+		return p, err == nil
+	}
+	return 0, false
+}
+
+// namePosition returns the position of the first name quoted in the error
+// message which occurs exactly once in the sql
+func namePosition(sql string, pgError map[string]interface{}) (int, bool) {
+	message, _ := pgError["message"].(string)
+	for _, match := range quotedNameRegex.FindAllStringSubmatch(message, -1) {
+		nameRegex, err := regexp.Compile(`(?i)(?:^|[^\w$])("?` + regexp.QuoteMeta(match[1]) + `"?)(?:[^\w$]|$)`)
+		if err != nil {
+			continue
+		}
+		matches := nameRegex.FindAllStringSubmatchIndex(sql, -1)
+		if len(matches) != 1 {
+			continue
+		}
+		return len([]rune(sql[:matches[0][2]])) + 1, true
+	}
+	return 0, false
+}
+
+// statementSnippet returns the lines of the statement containing offset
+// around line, with a caret under column. The lines are numbered as lines
+// of the file the sql starts on firstLine of.
+func statementSnippet(sql string, offset, line, column, firstLine int) string {
+	runes := []rune(sql)
+	lineOf := func(offset int) int {
+		return strings.Count(string(runes[:offset]), "\n") + 1
+	}
+	// the statement starts on the line of its first code, and ends on
+	// the line of its terminating semicolon
+	first, last := line, line
+	start := 0
+	for _, s := range sqlparse.Split(sql) {
+		length := len([]rune(s.Text))
+		if offset >= start && offset < start+length {
+			first = s.Line
+			last = lineOf(start + len([]rune(strings.TrimRight(s.Text, " \t\r\n"))))
+			break
+		}
+		start += length
+	}
+	if first > line || first == 0 {
+		first = line
+	}
+	if last < line {
+		last = line
+	}
+	if first < line-snippetContext {
+		first = line - snippetContext
+	}
+	if last > line+snippetContext {
+		last = line + snippetContext
+	}
+
+	lines := strings.Split(sql, "\n")
+	width := len(strconv.Itoa(last + firstLine - 1))
+	var b strings.Builder
+	for i := first; i <= last && i <= len(lines); i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %*d | %s\n", marker, width, i+firstLine-1, strings.TrimRight(lines[i-1], "\r"))
+		if i == line {
+			fmt.Fprintf(&b, "  %s | %s^\n", strings.Repeat(" ", width), caretIndent(lines[i-1], column))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// caretIndent returns the whitespace to put a caret under column of line,
+// keeping the tabs of the line
+func caretIndent(line string, column int) string {
+	var b strings.Builder
+	for i, c := range []rune(line) {
+		if i >= column-1 {
+			break
+		}
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package hasuradb
+
+import (
+	"testing"
+)
+
+func TestNewSQLLocation(t *testing.T) {
+	sql := "CREATE TABLE users (\n  id int\n);\n\nCREATE TABLE posts (\n  id int,\n  author int REFERENCES authors (id)\n);\n"
+	tests := []struct {
+		name        string
+		internal    interface{}
+		firstLine   int
+		line        int
+		column      int
+		approximate bool
+		snippet     string
+	}{
+		{
+			"position reported by postgres",
+			map[string]interface{}{
+				"error": map[string]interface{}{
+					"message":  `syntax error at or near "int"`,
+					"position": "75",
+				},
+			},
+			1, 7, 10, false,
+			"  5 | CREATE TABLE posts (\n  6 |   id int,\n> 7 |   author int REFERENCES authors (id)\n    |          ^\n  8 | );",
+		},
+		{
+			"section of a single file migration",
+			map[string]interface{}{
+				"error": map[string]interface{}{
+					"message":  `syntax error at or near "int"`,
+					"position": "75",
+				},
+			},
+			4, 10, 10, false,
+			"   8 | CREATE TABLE posts (\n   9 |   id int,\n> 10 |   author int REFERENCES authors (id)\n     |          ^\n  11 | );",
+		},
+		{
+			"name in the message",
+			map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": `relation "authors" does not exist`,
+				},
+			},
+			1, 7, 25, true,
+			"  5 | CREATE TABLE posts (\n  6 |   id int,\n> 7 |   author int REFERENCES authors (id)\n    |                         ^\n  8 | );",
+		},
+		{
+			"name occurs more than once",
+			map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": `column "id" specified more than once`,
+				},
+			},
+			1, 0, 0, false, "",
+		},
+		{
+			"not a postgres error",
+			map[string]interface{}{
+				"definition": "users",
+			},
+			1, 0, 0, false, "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newSQLLocation(sql, tt.firstLine, tt.internal)
+			if tt.line == 0 {
+				if l != nil {
+					t.Fatalf("expected no location, got %s", l)
+				}
+				return
+			}
+			if l == nil {
+				t.Fatal("expected a location")
+			}
+			if l.line != tt.line || l.column != tt.column || l.approximate != tt.approximate {
+				t.Errorf("expected line %d, column %d, approximate %t, got %s", tt.line, tt.column, tt.approximate, l)
+			}
+			if l.snippet != tt.snippet {
+				t.Errorf("expected snippet\n%s\ngot\n%s", tt.snippet, l.snippet)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package hasuradb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+)
+
+const (
+	DefaultLockTable = "migration_lock"
+	// DefaultLockTimeout is the time Lock waits for another process to
+	// release the lock. Migrate waits for migrate.DefaultLockTimeout more,
+	// so that the driver can report the holder of the lock.
+	DefaultLockTimeout = 10 * time.Second
+)
+
+// lockPollInterval is the time between two attempts to acquire the lock
+var lockPollInterval = 1 * time.Second
+
+// lockTableFor returns the default lock table of a migrations table
+func lockTableFor(migrationsTable string) string {
+	if migrationsTable != DefaultMigrationsTable {
+		// keep a separate lock for each migrations table
+		return migrationsTable + "_lock"
+	}
+	return DefaultLockTable
+}
+
+// ensureLockTable creates the table used to hold the migrations lock, if it
+// does not exist yet. The table has at most one row, which is present only
+// while a process holds the lock.
+func (h *HasuraDB) ensureLockTable() error {
+	columns, err := h.tableColumns(h.config.Schema, h.config.LockTable)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		return nil
+	}
+
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `CREATE TABLE IF NOT EXISTS ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.LockTable) + ` (id integer not null primary key default 1 check (id = 1), holder text not null, acquired_at timestamptz not null default now())`,
+		},
+	}
+
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		h.logger.Debug(err)
+		return err
+	}
+	h.logger.Debug("response: ", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
+	return nil
+}
+
+// acquireLock tries to insert the lock row until it succeeds or the lock
+// timeout expires. On timeout it returns database.ErrLockHeld with the
+// current holder of the lock.
+func (h *HasuraDB) acquireLock() error {
+	deadline := time.Now().Add(h.config.LockTimeout)
+	for {
+		ok, err := h.tryLock()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		holder, since, err := h.lockHolder()
+		if err != nil {
+			return err
+		}
+		if holder == "" {
+			// lock was released in between, retry immediately
+			continue
+		}
+		if time.Now().Add(lockPollInterval).After(deadline) {
+			return database.ErrLockHeld{
+				Holder: holder,
+				Since:  since,
+			}
+		}
+		h.logger.Debugf("migrations are locked by %s, retrying in %s", holder, lockPollInterval)
+		time.Sleep(lockPollInterval)
+	}
+}
+
+func (h *HasuraDB) tryLock() (bool, error) {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.LockTable) + ` (holder) VALUES (` + quoteLiteral(h.config.lockHolder) + `) ON CONFLICT (id) DO NOTHING RETURNING holder`,
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return false, err
+	}
+	// the first row is the header row
+	return len(hres.Result) > 1, nil
+}
+
+func (h *HasuraDB) lockHolder() (holder string, since string, err error) {
+	return h.lockHolderIn(h.config.Schema, h.config.LockTable)
+}
+
+// lockHolderIn returns the holder of the lock in a lock table, none if the
+// lock is not held
+func (h *HasuraDB) lockHolderIn(schema, table string) (holder string, since string, err error) {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `SELECT holder, acquired_at FROM ` + fmt.Sprintf("%s.%s", schema, table),
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return "", "", err
+	}
+	if len(hres.Result) < 2 {
+		return "", "", nil
+	}
+	return hres.Result[1][0], hres.Result[1][1], nil
+}
+
+// releaseLock removes the lock row if it is held by this process
+func (h *HasuraDB) releaseLock() error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `DELETE FROM ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.LockTable) + ` WHERE holder = ` + quoteLiteral(h.config.lockHolder),
+		},
+	}
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
+	return nil
+}
+
+// ForceUnLock removes the lock row, whichever process holds it
+func (h *HasuraDB) ForceUnLock() (string, error) {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `DELETE FROM ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.LockTable) + ` RETURNING holder`,
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return "", err
+	}
+	// the first row is the header row
+	if len(hres.Result) < 2 {
+		return "", nil
+	}
+	return hres.Result[1][0], nil
+}
+
+// sendSQLQuery sends a run_sql query which is expected to return tuples
+func (h *HasuraDB) sendSQLQuery(query HasuraQuery) (*HasuraSQLRes, error) {
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		h.logger.Debug(err)
+		return nil, err
+	}
+	h.logger.Debug("response: ", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewHasuraError(body, h.config.isCMD)
+	}
+
+	var hres HasuraSQLRes
+	err = json.Unmarshal(body, &hres)
+	if err != nil {
+		return nil, err
+	}
+	if hres.ResultType != TuplesOK {
+		return nil, fmt.Errorf("Invalid result Type %s", hres.ResultType)
+	}
+	return &hres, nil
+}
+
+// newLockHolder returns a string identifying this process, of the
+// form user@host (pid 123)
+func newLockHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s (pid %d)", osUsername(), hostname, os.Getpid())
+}
+
+// osUsername returns the name of the user running the process
+func osUsername() string {
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	if username == "" {
+		username = "unknown"
+	}
+	return username
+}
+
+// quoteLiteral quotes a string to be used as a literal in SQL
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
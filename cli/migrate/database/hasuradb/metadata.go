@@ -7,6 +7,7 @@ import (
 	"os"
 
 	gyaml "github.com/ghodss/yaml"
+	"github.com/hasura/graphql-engine/cli/metadata/diff"
 	"github.com/hasura/graphql-engine/cli/metadata/types"
 	"github.com/hasura/graphql-engine/cli/migrate/database"
 	"github.com/pkg/errors"
@@ -23,7 +24,13 @@ func (h *HasuraDB) EnableCheckMetadataConsistency(enabled bool) {
 	h.config.enableCheckMetadataConsistency = enabled
 }
 
-func (h *HasuraDB) ExportMetadata() (map[string][]byte, error) {
+// EnableIncrementalMetadata makes ApplyMetadata send only the queries for
+// the changes from the server metadata
+func (h *HasuraDB) EnableIncrementalMetadata(enabled bool) {
+	h.config.incrementalMetadata = enabled
+}
+
+func (h *HasuraDB) exportMetadata() (yaml.MapSlice, error) {
 	query := HasuraQuery{
 		Type: "export_metadata",
 		Args: HasuraArgs{},
@@ -46,6 +53,14 @@ func (h *HasuraDB) ExportMetadata() (map[string][]byte, error) {
 		h.logger.Debug(err)
 		return nil, err
 	}
+	return c, nil
+}
+
+func (h *HasuraDB) ExportMetadata() (map[string][]byte, error) {
+	c, err := h.exportMetadata()
+	if err != nil {
+		return nil, err
+	}
 
 	metadataFiles := make(map[string][]byte)
 	for _, plg := range h.config.Plugins {
@@ -167,6 +182,59 @@ func (h *HasuraDB) ApplyMetadata() error {
 	if err != nil {
 		return err
 	}
+	if h.config.incrementalMetadata {
+		// the metadata is only replaced if a change has no granular query,
+		// other errors are returned as they are
+		err := h.applyMetadataIncrementally(tmpMeta)
+		if _, ok := errors.Cause(err).(errNotIncremental); !ok {
+			return err
+		}
+		h.logger.Infof("%v, replacing the metadata", err)
+	}
+	return h.replaceMetadata(tmpMeta)
+}
+
+// applyMetadataIncrementally sends the granular metadata queries for the
+// changes from the server metadata to tmpMeta in a single bulk query
+func (h *HasuraDB) applyMetadataIncrementally(tmpMeta yaml.MapSlice) error {
+	serverMeta, err := h.exportMetadata()
+	if err != nil {
+		return errors.Wrap(err, "cannot export metadata from server")
+	}
+	changes, err := diff.Diff(serverMeta, tmpMeta)
+	if err != nil {
+		return err
+	}
+	queries, err := incrementalQueries(changes)
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		h.logger.Debug("metadata is up to date")
+		return nil
+	}
+	query := HasuraInterfaceBulk{
+		Type: "bulk",
+	}
+	for _, q := range queries {
+		query.Args = append(query.Args, q)
+	}
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		h.logger.Debug(err)
+		return err
+	}
+	h.logger.Debug("response: ", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrap(NewHasuraError(body, h.config.isCMD), "cannot apply metadata incrementally")
+	}
+	h.logger.Debugf("applied %d metadata change(s) incrementally", len(changes))
+	return nil
+}
+
+// replaceMetadata clears the server metadata and replaces it with tmpMeta
+func (h *HasuraDB) replaceMetadata(tmpMeta yaml.MapSlice) error {
 	yByt, err := yaml.Marshal(tmpMeta)
 	if err != nil {
 		return err
@@ -0,0 +1,346 @@
+package hasuradb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/metadata/diff"
+)
+
+// errNotIncremental is returned when a change of the metadata cannot be
+// applied with the granular metadata queries
+type errNotIncremental struct {
+	change diff.Change
+}
+
+func (e errNotIncremental) Error() string {
+	if e.change.Kind == "metadata" {
+		var paths []string
+		for _, field := range e.change.Fields {
+			paths = append(paths, field.Path)
+		}
+		return fmt.Sprintf("changes to %s cannot be applied incrementally", strings.Join(paths, ", "))
+	}
+	return fmt.Sprintf("%s %s cannot be applied incrementally", e.change.Type, e.change)
+}
+
+// The phases of the incremental queries. Objects are dropped before the
+// objects they depend on and created after them.
+const (
+	phaseDropPermission = iota
+	phaseDropEventTrigger
+	phaseDropComputedField
+	phaseDropRemoteRelationship
+	phaseDropRelationship
+	phaseDropAllowlist
+	phaseDropQueryCollection
+	phaseDropActionPermission
+	phaseDropAction
+	phaseDropCronTrigger
+	phaseDropFunction
+	phaseDropTable
+	phaseDropRemoteSchema
+	phaseSetCustomTypes
+	phaseCreateRemoteSchema
+	phaseCreateTable
+	phaseCreateFunction
+	phaseCreateRelationship
+	phaseCreateRemoteRelationship
+	phaseCreateComputedField
+	phaseCreatePermission
+	phaseCreateEventTrigger
+	phaseCreateQueryCollection
+	phaseCreateAllowlist
+	phaseCreateAction
+	phaseCreateActionPermission
+	phaseCreateCronTrigger
+)
+
+type incrementalQuery struct {
+	phase int
+	query HasuraInterfaceQuery
+}
+
+// incrementalQueries returns the granular metadata queries which apply the
+// changes, in the order they have to be sent in
+func incrementalQueries(changes []diff.Change) ([]HasuraInterfaceQuery, error) {
+	var planned []incrementalQuery
+	add := func(phase int, query HasuraInterfaceQuery) {
+		planned = append(planned, incrementalQuery{phase, query})
+	}
+	for _, change := range expandChanges(changes) {
+		if err := planChange(change, add); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(planned, func(i, j int) bool {
+		return planned[i].phase < planned[j].phase
+	})
+	queries := make([]HasuraInterfaceQuery, 0, len(planned))
+	for _, q := range planned {
+		queries = append(queries, q.query)
+	}
+	return queries, nil
+}
+
+// expandChanges adds the objects defined on added objects, e.g. the
+// permissions of an added table. The objects defined on removed tables are
+// dropped along with the table.
+func expandChanges(changes []diff.Change) []diff.Change {
+	var expanded []diff.Change
+	for _, change := range changes {
+		expanded = append(expanded, change)
+		if change.Type == diff.Added {
+			expanded = append(expanded, expandChanges(diff.Children(change))...)
+		}
+		if change.Type == diff.Removed && change.Kind == "action" {
+			// permissions are not dropped along with the action
+			expanded = append(expanded, diff.Children(change)...)
+		}
+	}
+	return expanded
+}
+
+// planChange adds the queries for a change
+func planChange(change diff.Change, add func(int, HasuraInterfaceQuery)) error {
+	switch change.Kind {
+	case "metadata":
+		for _, field := range change.Fields {
+			if !strings.HasPrefix(field.Path+".", "custom_types.") {
+				return errNotIncremental{change}
+			}
+		}
+		customTypes := change.After["custom_types"]
+		if customTypes == nil {
+			customTypes = map[string]interface{}{}
+		}
+		add(phaseSetCustomTypes, HasuraInterfaceQuery{Type: setCustomTypes, Args: customTypes})
+		return nil
+	case "table":
+		switch change.Type {
+		case diff.Added:
+			if _, ok := change.After["configuration"]; ok {
+				return errNotIncremental{change}
+			}
+			args := map[string]interface{}{"table": change.After["table"]}
+			if isEnum, ok := change.After["is_enum"]; ok {
+				args["is_enum"] = isEnum
+			}
+			add(phaseCreateTable, HasuraInterfaceQuery{Type: trackTable, Args: args})
+		case diff.Removed:
+			add(phaseDropTable, HasuraInterfaceQuery{Type: untrackTable, Args: map[string]interface{}{"table": change.Before["table"], "cascade": true}})
+		default:
+			return errNotIncremental{change}
+		}
+		return nil
+	case "function":
+		if change.Type != diff.Added {
+			add(phaseDropFunction, HasuraInterfaceQuery{Type: unTrackFunction, Args: qualifiedFunction(change.Before["function"])})
+		}
+		if change.Type != diff.Removed {
+			if _, ok := change.After["configuration"]; ok {
+				add(phaseCreateFunction, HasuraInterfaceQuery{Type: trackFunction, Version: v2, Args: change.After})
+			} else {
+				add(phaseCreateFunction, HasuraInterfaceQuery{Type: trackFunction, Args: qualifiedFunction(change.After["function"])})
+			}
+		}
+		return nil
+	case "event_trigger":
+		if change.Type == diff.Removed {
+			add(phaseDropEventTrigger, HasuraInterfaceQuery{Type: deleteEventTrigger, Args: map[string]interface{}{"name": change.Name}})
+			return nil
+		}
+		// an event trigger is replaced in place, to keep its events
+		args := withParent(change.After, "table", change.ParentKey)
+		flattenDefinition(args)
+		if change.Type == diff.Changed {
+			args["replace"] = true
+		}
+		add(phaseCreateEventTrigger, HasuraInterfaceQuery{Type: createEventTrigger, Args: args})
+		return nil
+	case "cron_trigger":
+		if change.Type == diff.Removed {
+			add(phaseDropCronTrigger, HasuraInterfaceQuery{Type: deleteCronTrigger, Args: map[string]interface{}{"name": change.Name}})
+			return nil
+		}
+		args := withParent(change.After, "", nil)
+		if change.Type == diff.Changed {
+			args["replace"] = true
+		}
+		add(phaseCreateCronTrigger, HasuraInterfaceQuery{Type: createCronTrigger, Args: args})
+		return nil
+	case "action":
+		switch change.Type {
+		case diff.Added:
+			add(phaseCreateAction, HasuraInterfaceQuery{Type: createAction, Args: change.After})
+		case diff.Removed:
+			add(phaseDropAction, HasuraInterfaceQuery{Type: dropAction, Args: map[string]interface{}{"name": change.Name}})
+		default:
+			for _, field := range change.Fields {
+				if !strings.HasPrefix(field.Path+".", "definition.") {
+					return errNotIncremental{change}
+				}
+			}
+			add(phaseCreateAction, HasuraInterfaceQuery{Type: updateAction, Args: map[string]interface{}{"name": change.Name, "definition": change.After["definition"]}})
+		}
+		return nil
+	case "remote_schema", "query_collection":
+		// other objects depend on them, so they cannot be dropped and
+		// created again
+		if change.Type == diff.Changed {
+			return errNotIncremental{change}
+		}
+	}
+
+	kind, ok := incrementalKinds[change.Kind]
+	if !ok {
+		return errNotIncremental{change}
+	}
+	if change.Type != diff.Added {
+		add(kind.dropPhase, HasuraInterfaceQuery{Type: kind.drop, Args: kind.dropArgs(change)})
+	}
+	if change.Type != diff.Removed {
+		args := withParent(change.After, kind.parentField, change.ParentKey)
+		if kind.flatten {
+			flattenDefinition(args)
+		}
+		add(kind.createPhase, HasuraInterfaceQuery{Type: kind.create, Args: args})
+	}
+	return nil
+}
+
+// incrementalKind holds the queries of objects which are changed by
+// dropping and creating them again
+type incrementalKind struct {
+	create      requestTypes
+	createPhase int
+	drop        requestTypes
+	dropPhase   int
+	// dropArgs returns the arguments of the drop query
+	dropArgs func(change diff.Change) interface{}
+	// parentField is the argument holding the key of the parent object
+	parentField string
+	// flatten is set when the fields of the definition of the object are
+	// arguments of the create query
+	flatten bool
+}
+
+var incrementalKinds = map[string]incrementalKind{
+	"object_relationship": {
+		create: createObjectRelationship, createPhase: phaseCreateRelationship,
+		drop: dropRelationship, dropPhase: phaseDropRelationship,
+		dropArgs:    dropArgs("table", "relationship"),
+		parentField: "table",
+	},
+	"array_relationship": {
+		create: createArrayRelationship, createPhase: phaseCreateRelationship,
+		drop: dropRelationship, dropPhase: phaseDropRelationship,
+		dropArgs:    dropArgs("table", "relationship"),
+		parentField: "table",
+	},
+	"remote_relationship": {
+		create: createRemoteRelationship, createPhase: phaseCreateRemoteRelationship,
+		drop: deleteRemoteRelationship, dropPhase: phaseDropRemoteRelationship,
+		dropArgs:    dropArgs("table", "name"),
+		parentField: "table",
+		flatten:     true,
+	},
+	"computed_field": {
+		create: addComputedField, createPhase: phaseCreateComputedField,
+		drop: dropComputedField, dropPhase: phaseDropComputedField,
+		dropArgs:    dropArgs("table", "name"),
+		parentField: "table",
+	},
+	"insert_permission": {
+		create: createInsertPermission, createPhase: phaseCreatePermission,
+		drop: dropInsertPermission, dropPhase: phaseDropPermission,
+		dropArgs:    dropArgs("table", "role"),
+		parentField: "table",
+	},
+	"select_permission": {
+		create: createSelectPermission, createPhase: phaseCreatePermission,
+		drop: dropSelectPermission, dropPhase: phaseDropPermission,
+		dropArgs:    dropArgs("table", "role"),
+		parentField: "table",
+	},
+	"update_permission": {
+		create: createUpdatePermission, createPhase: phaseCreatePermission,
+		drop: dropUpdatePermission, dropPhase: phaseDropPermission,
+		dropArgs:    dropArgs("table", "role"),
+		parentField: "table",
+	},
+	"delete_permission": {
+		create: createDeletePermission, createPhase: phaseCreatePermission,
+		drop: dropDeletePermission, dropPhase: phaseDropPermission,
+		dropArgs:    dropArgs("table", "role"),
+		parentField: "table",
+	},
+	"remote_schema": {
+		create: addRemoteSchema, createPhase: phaseCreateRemoteSchema,
+		drop: removeRemoteSchema, dropPhase: phaseDropRemoteSchema,
+		dropArgs: dropArgs("", "name"),
+	},
+	"query_collection": {
+		create: createQueryCollection, createPhase: phaseCreateQueryCollection,
+		drop: dropQueryCollection, dropPhase: phaseDropQueryCollection,
+		dropArgs: dropArgs("", "collection"),
+	},
+	"allowlist": {
+		create: addCollectionToAllowList, createPhase: phaseCreateAllowlist,
+		drop: dropCollectionFromAllowList, dropPhase: phaseDropAllowlist,
+		dropArgs: dropArgs("", "collection"),
+	},
+	"action_permission": {
+		create: createActionPermission, createPhase: phaseCreateActionPermission,
+		drop: dropActionPermission, dropPhase: phaseDropActionPermission,
+		dropArgs:    dropArgs("action", "role"),
+		parentField: "action",
+	},
+}
+
+// dropArgs returns the arguments of a drop query, which are the key of the
+// parent object and the name of the object
+func dropArgs(parentField, nameField string) func(diff.Change) interface{} {
+	return func(change diff.Change) interface{} {
+		args := map[string]interface{}{nameField: change.Name}
+		if parentField != "" {
+			args[parentField] = change.ParentKey
+		}
+		return args
+	}
+}
+
+// withParent returns a copy of object with the key of its parent object
+func withParent(object map[string]interface{}, parentField string, parentKey interface{}) map[string]interface{} {
+	args := make(map[string]interface{}, len(object)+1)
+	for k, v := range object {
+		args[k] = v
+	}
+	if parentField != "" {
+		args[parentField] = parentKey
+	}
+	return args
+}
+
+// qualifiedFunction returns the {schema, name} of a function which is
+// tracked by its name
+func qualifiedFunction(function interface{}) interface{} {
+	if name, ok := function.(string); ok {
+		return map[string]interface{}{"schema": "public", "name": name}
+	}
+	return function
+}
+
+// flattenDefinition moves the fields of the definition of an object to the
+// object itself
+func flattenDefinition(args map[string]interface{}) {
+	definition, ok := args["definition"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(args, "definition")
+	for k, v := range definition {
+		args[k] = v
+	}
+}
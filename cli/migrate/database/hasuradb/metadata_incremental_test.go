@@ -0,0 +1,126 @@
+package hasuradb
+
+import (
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/metadata/diff"
+	"gopkg.in/yaml.v2"
+)
+
+func TestIncrementalQueries(t *testing.T) {
+	server := `
+version: 2
+tables:
+- table: {schema: public, name: users}
+  select_permissions:
+  - role: user
+    permission: {columns: [id], filter: {}}
+  event_triggers:
+  - name: user_created
+    definition: {enable_manual: false, insert: {columns: "*"}}
+    webhook: https://example.com/old
+- table: {schema: public, name: sessions}
+`
+	tests := []struct {
+		name     string
+		local    string
+		expected []requestTypes
+	}{
+		{
+			"granular changes",
+			`
+version: 2
+tables:
+- table: {schema: public, name: users}
+  select_permissions:
+  - role: user
+    permission: {columns: [id, name], filter: {}}
+  event_triggers:
+  - name: user_created
+    definition: {enable_manual: false, insert: {columns: "*"}}
+    webhook: https://example.com/new
+- table: {schema: public, name: posts}
+  object_relationships:
+  - name: author
+    using: {foreign_key_constraint_on: author_id}
+  select_permissions:
+  - role: user
+    permission: {columns: [id], filter: {author: {id: {_eq: X-Hasura-User-Id}}}}
+`,
+			[]requestTypes{
+				dropSelectPermission,
+				untrackTable,
+				trackTable,
+				createObjectRelationship,
+				createSelectPermission,
+				createSelectPermission,
+				createEventTrigger,
+			},
+		},
+		{
+			"no changes",
+			server,
+			[]requestTypes{},
+		},
+		{
+			"changed table configuration",
+			`
+version: 2
+tables:
+- table: {schema: public, name: users}
+  configuration: {custom_root_fields: {select: people}}
+  select_permissions:
+  - role: user
+    permission: {columns: [id], filter: {}}
+  event_triggers:
+  - name: user_created
+    definition: {enable_manual: false, insert: {columns: "*"}}
+    webhook: https://example.com/old
+- table: {schema: public, name: sessions}
+`,
+			nil,
+		},
+	}
+	var serverMeta yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(server), &serverMeta); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var localMeta yaml.MapSlice
+			if err := yaml.Unmarshal([]byte(tt.local), &localMeta); err != nil {
+				t.Fatal(err)
+			}
+			changes, err := diff.Diff(serverMeta, localMeta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			queries, err := incrementalQueries(changes)
+			if tt.expected == nil {
+				if _, ok := err.(errNotIncremental); !ok {
+					t.Fatalf("expected the changes not to be incremental, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(queries) != len(tt.expected) {
+				t.Fatalf("expected %d queries, got %+v", len(tt.expected), queries)
+			}
+			for i, query := range queries {
+				if query.Type != tt.expected[i] {
+					t.Errorf("expected query %d to be %s, got %s", i, tt.expected[i], query.Type)
+				}
+			}
+			for _, query := range queries {
+				if query.Type == createEventTrigger {
+					args := query.Args.(map[string]interface{})
+					if args["replace"] != true || args["insert"] == nil || args["table"] == nil {
+						t.Errorf("expected the event trigger to be replaced on its table, got %v", args)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package hasuradb
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ensureRepeatableTable creates the table which holds the checksums of the
+// applied repeatable migrations
+func (h *HasuraDB) ensureRepeatableTable() error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `CREATE TABLE IF NOT EXISTS ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.RepeatableTable) + ` (name text not null primary key, checksum text not null, applied_at timestamptz not null default now())`,
+		},
+	}
+
+	resp, body, err := h.sendv1Query(query)
+	if err != nil {
+		h.logger.Debug(err)
+		return err
+	}
+	h.logger.Debug("response: ", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return NewHasuraError(body, h.config.isCMD)
+	}
+	return nil
+}
+
+func (h *HasuraDB) SetRepeatableChecksum(name string, checksum string) error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.RepeatableTable) + ` (name, checksum, applied_at) VALUES (` + quoteLiteral(name) + `, ` + quoteLiteral(checksum) + `, clock_timestamp()) ON CONFLICT (name) DO UPDATE SET checksum = excluded.checksum, applied_at = excluded.applied_at`,
+		},
+	}
+	h.migrationQuery.Args = append(h.migrationQuery.Args, query)
+	return nil
+}
+
+func (h *HasuraDB) RepeatableChecksums() map[string]string {
+	return h.migrations.RepeatableChecksums()
+}
+
+func (h *HasuraDB) getRepeatables() error {
+	query := HasuraQuery{
+		Type: "run_sql",
+		Args: HasuraArgs{
+			SQL: `SELECT name, checksum FROM ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.RepeatableTable),
+		},
+	}
+	hres, err := h.sendSQLQuery(query)
+	if err != nil {
+		return err
+	}
+	for index, val := range hres.Result {
+		if index == 0 {
+			continue
+		}
+		h.migrations.SetRepeatableChecksum(val[0], val[1])
+	}
+	return nil
+}
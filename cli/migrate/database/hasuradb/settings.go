@@ -15,7 +15,7 @@ func (h *HasuraDB) ensureSettingsTable() error {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `SELECT COUNT(1) FROM information_schema.tables WHERE table_name = '` + h.config.SettingsTable + `' AND table_schema = '` + DefaultSchema + `' LIMIT 1`,
+			SQL: `SELECT COUNT(1) FROM information_schema.tables WHERE table_name = '` + h.config.SettingsTable + `' AND table_schema = '` + h.config.Schema + `' LIMIT 1`,
 		},
 	}
 
@@ -50,7 +50,7 @@ func (h *HasuraDB) ensureSettingsTable() error {
 	query = HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `CREATE TABLE ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.SettingsTable) + ` (setting text not null primary key, value text not null)`,
+			SQL: `CREATE TABLE ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.SettingsTable) + ` (setting text not null primary key, value text not null)`,
 		},
 	}
 
@@ -84,7 +84,7 @@ func (h *HasuraDB) setDefaultSettings() error {
 		sql := HasuraQuery{
 			Type: "run_sql",
 			Args: HasuraArgs{
-				SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.SettingsTable) + ` (setting, value) VALUES ('` + fmt.Sprintf("%s", setting.GetName()) + `', '` + fmt.Sprintf("%s", setting.GetDefaultValue()) + `')`,
+				SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.SettingsTable) + ` (setting, value) VALUES ('` + fmt.Sprintf("%s", setting.GetName()) + `', '` + fmt.Sprintf("%s", setting.GetDefaultValue()) + `')`,
 			},
 		}
 		query.Args = append(query.Args, sql)
@@ -110,7 +110,7 @@ func (h *HasuraDB) GetSetting(name string) (value string, err error) {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `SELECT value from ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.SettingsTable) + ` where setting='` + name + `'`,
+			SQL: `SELECT value from ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.SettingsTable) + ` where setting='` + name + `'`,
 		},
 	}
 
@@ -152,7 +152,7 @@ func (h *HasuraDB) UpdateSetting(name string, value string) error {
 	query := HasuraQuery{
 		Type: "run_sql",
 		Args: HasuraArgs{
-			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", DefaultSchema, h.config.SettingsTable) + ` (setting, value) VALUES ('` + name + `', '` + value + `') ON CONFLICT (setting) DO UPDATE SET value='` + value + `'`,
+			SQL: `INSERT INTO ` + fmt.Sprintf("%s.%s", h.config.Schema, h.config.SettingsTable) + ` (setting, value) VALUES ('` + name + `', '` + value + `') ON CONFLICT (setting) DO UPDATE SET value='` + value + `'`,
 		},
 	}
 
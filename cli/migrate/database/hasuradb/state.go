@@ -0,0 +1,167 @@
+package hasuradb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+)
+
+// stateTables are the tables holding the migrations state
+type stateTables struct {
+	schema     string
+	migrations string
+	settings   string
+	lock       string
+	history    string
+	repeatable string
+}
+
+// newStateTables returns the tables of a state location, with the defaults
+// of the driver for the empty fields
+func newStateTables(location database.StateLocation) stateTables {
+	t := stateTables{
+		schema:     location.Schema,
+		migrations: location.MigrationsTable,
+		settings:   location.SettingsTable,
+	}
+	if t.schema == "" {
+		t.schema = DefaultSchema
+	}
+	if t.migrations == "" {
+		t.migrations = DefaultMigrationsTable
+	}
+	if t.settings == "" {
+		t.settings = DefaultSettingsTable
+	}
+	t.lock = lockTableFor(t.migrations)
+	t.history = t.migrations + "_history"
+	t.repeatable = t.migrations + "_repeatable"
+	return t
+}
+
+// MoveState queues the queries which copy the versions, settings, history and
+// repeatable migrations from the tables at the location from to the ones used
+// by the driver and drop the former. Tables which are already at their
+// target are left as they are. The queries are sent on UnLock.
+func (h *HasuraDB) MoveState(from database.StateLocation) error {
+	to := stateTables{
+		schema:     h.config.Schema,
+		migrations: h.config.MigrationsTable,
+		settings:   h.config.SettingsTable,
+		lock:       h.config.LockTable,
+		history:    h.config.HistoryTable,
+		repeatable: h.config.RepeatableTable,
+	}
+	src := newStateTables(from)
+	if src.schema != to.schema || src.lock != to.lock {
+		// the old lock is dropped, so no other process may hold it
+		columns, err := h.tableColumns(src.schema, src.lock)
+		if err != nil {
+			return err
+		}
+		if len(columns) > 0 {
+			holder, since, err := h.lockHolderIn(src.schema, src.lock)
+			if err != nil {
+				return err
+			}
+			if holder != "" {
+				return database.ErrLockHeld{Holder: holder, Since: since}
+			}
+		}
+	}
+	_, hasVersions := h.migrations.Last()
+	columns := func(table string) (map[string]bool, error) {
+		return h.tableColumns(src.schema, table)
+	}
+	sqls, err := moveStateSQL(src, to, hasVersions, columns)
+	if err != nil {
+		return err
+	}
+	for _, sql := range sqls {
+		h.migrationQuery.Args = append(h.migrationQuery.Args, HasuraQuery{
+			Type: "run_sql",
+			Args: HasuraArgs{
+				SQL: sql,
+			},
+		})
+	}
+	return nil
+}
+
+// moveStateSQL returns the queries which move the state from the tables
+// from to the tables to. hasVersions is set when the migrations table to
+// already has applied versions, and columns returns the columns of a table
+// in the schema of from, none if it does not exist.
+func moveStateSQL(from, to stateTables, hasVersions bool, columns func(table string) (map[string]bool, error)) ([]string, error) {
+	// moved reports whether a table is not already at its target
+	moved := func(fromTable, toTable string) bool {
+		return from.schema != to.schema || fromTable != toTable
+	}
+	if !moved(from.migrations, to.migrations) && !moved(from.settings, to.settings) {
+		return nil, fmt.Errorf("migrations state is already at %s.%s", from.schema, from.migrations)
+	}
+
+	var sqls, drops []string
+	if moved(from.migrations, to.migrations) {
+		if hasVersions {
+			return nil, fmt.Errorf("%s.%s already has applied versions", to.schema, to.migrations)
+		}
+		versionColumns, err := columns(from.migrations)
+		if err != nil {
+			return nil, err
+		}
+		if len(versionColumns) == 0 {
+			return nil, fmt.Errorf("migrations table %s.%s does not exist", from.schema, from.migrations)
+		}
+		// the old table may have been created by an older version of the cli
+		names := []string{"version", "dirty"}
+		for _, column := range versionTableColumns {
+			if versionColumns[column.name] {
+				names = append(names, column.name)
+			}
+		}
+		sqls = append(sqls, fmt.Sprintf(`INSERT INTO %s.%s (%s) SELECT %[3]s FROM %s.%s`, to.schema, to.migrations, strings.Join(names, ", "), from.schema, from.migrations))
+		drops = append(drops, fmt.Sprintf("%s.%s", from.schema, from.migrations))
+	}
+
+	tables := []struct {
+		from, to string
+		// columns are copied along with the optional columns which exist
+		// in the old table, suffix is appended to the copying query
+		columns, optional []string
+		suffix            string
+	}{
+		{from.settings, to.settings, []string{"setting", "value"}, nil, ` ON CONFLICT (setting) DO UPDATE SET value = excluded.value`},
+		{from.history, to.history, []string{"version", "direction", "applied_at", "applied_by", "cli_version", "duration_ms"}, []string{"kind"}, ` ORDER BY id`},
+		{from.repeatable, to.repeatable, []string{"name", "checksum", "applied_at"}, nil, ` ON CONFLICT (name) DO UPDATE SET checksum = excluded.checksum, applied_at = excluded.applied_at`},
+		// the lock is held in the new lock table while moving
+		{from.lock, to.lock, nil, nil, ""},
+	}
+	for _, table := range tables {
+		if !moved(table.from, table.to) {
+			continue
+		}
+		tableColumns, err := columns(table.from)
+		if err != nil {
+			return nil, err
+		}
+		if len(tableColumns) == 0 {
+			continue
+		}
+		if len(table.columns) > 0 {
+			names := table.columns
+			for _, column := range table.optional {
+				if tableColumns[column] {
+					names = append(names, column)
+				}
+			}
+			sqls = append(sqls, fmt.Sprintf(`INSERT INTO %s.%s (%s) SELECT %[3]s FROM %s.%s%s`, to.schema, table.to, strings.Join(names, ", "), from.schema, table.from, table.suffix))
+		}
+		drops = append(drops, fmt.Sprintf("%s.%s", from.schema, table.from))
+	}
+	if len(drops) > 0 {
+		sqls = append(sqls, "DROP TABLE "+strings.Join(drops, ", "))
+	}
+	return sqls, nil
+}
@@ -0,0 +1,97 @@
+package hasuradb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+)
+
+func TestMoveStateSQL(t *testing.T) {
+	// every table of the default location exists
+	existing := map[string]bool{
+		"schema_migrations":            true,
+		"migration_settings":           true,
+		"migration_lock":               true,
+		"schema_migrations_history":    true,
+		"schema_migrations_repeatable": true,
+	}
+	columns := func(table string) (map[string]bool, error) {
+		if !existing[table] {
+			return nil, nil
+		}
+		if table == "schema_migrations_history" {
+			return map[string]bool{"version": true, "direction": true, "kind": true}, nil
+		}
+		return map[string]bool{"version": true, "dirty": true, "checksum": true}, nil
+	}
+	defaults := newStateTables(database.StateLocation{})
+	tests := []struct {
+		name        string
+		to          database.StateLocation
+		hasVersions bool
+		expected    []string
+		wantErr     bool
+	}{
+		{
+			"migrations table only",
+			database.StateLocation{MigrationsTable: "project_migrations"},
+			false,
+			[]string{
+				`INSERT INTO hdb_catalog.project_migrations (version, dirty, checksum) SELECT version, dirty, checksum FROM hdb_catalog.schema_migrations`,
+				`INSERT INTO hdb_catalog.project_migrations_history (version, direction, applied_at, applied_by, cli_version, duration_ms, kind) SELECT version, direction, applied_at, applied_by, cli_version, duration_ms, kind FROM hdb_catalog.schema_migrations_history ORDER BY id`,
+				`INSERT INTO hdb_catalog.project_migrations_repeatable (name, checksum, applied_at) SELECT name, checksum, applied_at FROM hdb_catalog.schema_migrations_repeatable ON CONFLICT (name) DO UPDATE SET checksum = excluded.checksum, applied_at = excluded.applied_at`,
+				`DROP TABLE hdb_catalog.schema_migrations, hdb_catalog.schema_migrations_history, hdb_catalog.schema_migrations_repeatable, hdb_catalog.migration_lock`,
+			},
+			false,
+		},
+		{
+			"settings table only",
+			database.StateLocation{SettingsTable: "project_settings"},
+			true,
+			[]string{
+				`INSERT INTO hdb_catalog.project_settings (setting, value) SELECT setting, value FROM hdb_catalog.migration_settings ON CONFLICT (setting) DO UPDATE SET value = excluded.value`,
+				`DROP TABLE hdb_catalog.migration_settings`,
+			},
+			false,
+		},
+		{
+			"schema",
+			database.StateLocation{Schema: "project"},
+			false,
+			[]string{
+				`INSERT INTO project.schema_migrations (version, dirty, checksum) SELECT version, dirty, checksum FROM hdb_catalog.schema_migrations`,
+				`INSERT INTO project.migration_settings (setting, value) SELECT setting, value FROM hdb_catalog.migration_settings ON CONFLICT (setting) DO UPDATE SET value = excluded.value`,
+				`INSERT INTO project.schema_migrations_history (version, direction, applied_at, applied_by, cli_version, duration_ms, kind) SELECT version, direction, applied_at, applied_by, cli_version, duration_ms, kind FROM hdb_catalog.schema_migrations_history ORDER BY id`,
+				`INSERT INTO project.schema_migrations_repeatable (name, checksum, applied_at) SELECT name, checksum, applied_at FROM hdb_catalog.schema_migrations_repeatable ON CONFLICT (name) DO UPDATE SET checksum = excluded.checksum, applied_at = excluded.applied_at`,
+				`DROP TABLE hdb_catalog.schema_migrations, hdb_catalog.migration_settings, hdb_catalog.schema_migrations_history, hdb_catalog.schema_migrations_repeatable, hdb_catalog.migration_lock`,
+			},
+			false,
+		},
+		{
+			"same location",
+			database.StateLocation{},
+			false,
+			nil,
+			true,
+		},
+		{
+			"migrations table with applied versions",
+			database.StateLocation{MigrationsTable: "project_migrations"},
+			true,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqls, err := moveStateSQL(defaults, newStateTables(tt.to), tt.hasVersions, columns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(sqls, tt.expected) {
+				t.Errorf("expected\n%v\ngot\n%v", tt.expected, sqls)
+			}
+		})
+	}
+}
@@ -282,6 +282,9 @@ type HasuraError struct {
 	// MigrationFile is used internally for hasuractl
 	migrationFile  string
 	migrationQuery string
+	location       *sqlLocation
+	// version is the version of the failed query, if known
+	version        int64
 	Path           string      `json:"path"`
 	ErrorMessage   string      `json:"error"`
 	Internal       interface{} `json:"internal,omitempty"`
@@ -335,10 +338,18 @@ type SchemaDump struct {
 func (h HasuraError) Error() string {
 	var errorStrings []string
 	errorStrings = append(errorStrings, fmt.Sprintf("[%s] %s (%s)", h.Code, h.ErrorMessage, h.Path))
-	if h.migrationFile != "" {
+	if h.version > 0 {
+		errorStrings = append(errorStrings, fmt.Sprintf("Version: %d", h.version))
+	}
+	if h.migrationFile != "" && h.location != nil {
+		errorStrings = append(errorStrings, fmt.Sprintf("File: '%s' (%s)", h.migrationFile, h.location))
+	} else if h.migrationFile != "" {
 		errorStrings = append(errorStrings, fmt.Sprintf("File: '%s'", h.migrationFile))
 	}
-	if h.migrationQuery != "" {
+	if h.location != nil {
+		// the failing statement instead of the whole query
+		errorStrings = append(errorStrings, h.location.snippet)
+	} else if h.migrationQuery != "" {
 		errorStrings = append(errorStrings, fmt.Sprintf("%s", h.migrationQuery))
 	}
 	var internalError SQLInternalError
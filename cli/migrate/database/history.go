@@ -0,0 +1,38 @@
+package database
+
+import "time"
+
+// Kinds of history entries, which tell how a version was recorded
+const (
+	// KindApply is a version which was run on the database
+	KindApply = "apply"
+	// KindSkip is a version recorded or removed without running it
+	KindSkip = "skip"
+	// KindForce is a version recorded by migrate force
+	KindForce = "force"
+	// KindBaseline is a version recorded by migrate baseline
+	KindBaseline = "baseline"
+	// KindRepair is a dirty version removed by migrate repair
+	KindRepair = "repair"
+)
+
+// HistoryEntry is a version applied or rolled back on the database
+type HistoryEntry struct {
+	Version uint64
+	// Direction is either up or down
+	Direction string
+	// Kind is one of the Kind constants, empty for the entries recorded
+	// by a version of the cli which did not record it
+	Kind       string
+	AppliedAt  time.Time
+	AppliedBy  string
+	CLIVersion string
+	// Duration is the execution time of the version, zero if unknown
+	Duration time.Duration
+}
+
+type HistoryDriver interface {
+	// GetHistory returns the versions applied or rolled back on
+	// the database, oldest first
+	GetHistory() ([]HistoryEntry, error)
+}
@@ -10,6 +10,8 @@ type MetadataDriver interface {
 
 	EnableCheckMetadataConsistency(bool)
 
+	EnableIncrementalMetadata(bool)
+
 	ExportMetadata() (map[string][]byte, error)
 
 	ResetMetadata() error
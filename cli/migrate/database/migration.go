@@ -5,12 +5,22 @@ import "sort"
 // Migrations wraps Migration and has an internal index
 // to keep track of Migration order in database.
 type Migrations struct {
-	index uint64Slice
+	index     uint64Slice
+	dirty     map[uint64]bool
+	checksums map[uint64]string
+
+	// repeatables holds the checksums of the applied
+	// repeatable migrations by name
+	repeatables map[string]string
 }
 
 func NewMigrations() *Migrations {
 	return &Migrations{
-		index: make(uint64Slice, 0),
+		index:     make(uint64Slice, 0),
+		dirty:     make(map[uint64]bool),
+		checksums: make(map[uint64]string),
+
+		repeatables: make(map[string]string),
 	}
 }
 
@@ -22,6 +32,48 @@ func (i *Migrations) Append(version uint64) {
 	sort.Sort(i.index)
 }
 
+// SetDirty marks an appended version as dirty
+func (i *Migrations) SetDirty(version uint64) {
+	i.dirty[version] = true
+}
+
+// IsDirty returns true if the version is marked as dirty
+func (i *Migrations) IsDirty(version uint64) bool {
+	return i.dirty[version]
+}
+
+// FirstDirty returns the first version marked as dirty
+func (i *Migrations) FirstDirty() (version uint64, ok bool) {
+	for _, v := range i.index {
+		if i.dirty[v] {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// SetChecksum sets the checksum of an appended version
+func (i *Migrations) SetChecksum(version uint64, checksum string) {
+	i.checksums[version] = checksum
+}
+
+// Checksum returns the checksum of the version, if any
+func (i *Migrations) Checksum(version uint64) (checksum string, ok bool) {
+	checksum, ok = i.checksums[version]
+	return checksum, ok
+}
+
+// SetRepeatableChecksum sets the checksum of an applied repeatable migration
+func (i *Migrations) SetRepeatableChecksum(name, checksum string) {
+	i.repeatables[name] = checksum
+}
+
+// RepeatableChecksums returns the checksums of the applied
+// repeatable migrations by name
+func (i *Migrations) RepeatableChecksums() map[string]string {
+	return i.repeatables
+}
+
 func (i *Migrations) First() (version uint64, ok bool) {
 	if len(i.index) == 0 {
 		return 0, false
@@ -0,0 +1,250 @@
+// Package lint inspects sql migrations for statements which lose data or
+// lock tables for a long time.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/migrate/sqlparse"
+)
+
+// Severity of a finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is a pattern the linter looks for
+type Rule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+}
+
+// IDs of the rules
+const (
+	RuleDropTable             = "drop-table"
+	RuleDropColumn            = "drop-column"
+	RuleAlterColumnType       = "alter-column-type"
+	RuleNotNullWithoutDefault = "not-null-without-default"
+	RuleIndexNotConcurrent    = "create-index-not-concurrently"
+	RuleMissingDown           = "missing-down"
+)
+
+// Rules holds all the rules of the linter
+var Rules = []Rule{
+	{RuleDropTable, "DROP TABLE deletes the table and its data", SeverityError},
+	{RuleDropColumn, "DROP COLUMN deletes the column and its data", SeverityError},
+	{RuleAlterColumnType, "changing the type of a column rewrites the table while holding an exclusive lock", SeverityWarning},
+	{RuleNotNullWithoutDefault, "a NOT NULL column without a default fails on tables which have rows", SeverityError},
+	{RuleIndexNotConcurrent, "CREATE INDEX without CONCURRENTLY blocks writes to the table while the index is built", SeverityWarning},
+	{RuleMissingDown, "the migration cannot be rolled back as its down.sql is missing or empty", SeverityWarning},
+}
+
+// GetRule returns the rule with the id
+func GetRule(id string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Migration holds the sql of a migration version to be linted
+type Migration struct {
+	Version uint64
+	// UpFile is the name of the up migration file, Up its content
+	UpFile string
+	Up     []byte
+	// DownFile is the name of the down migration file, Down its content.
+	// DownFile is empty if the migration has no down.sql.
+	DownFile string
+	Down     []byte
+	// UpLine and DownLine are the lines of their files the up and down sql
+	// start on, which are not the first line for the sections of single
+	// file migrations. 0 is the same as 1.
+	UpLine   int
+	DownLine int
+}
+
+// Finding is a statement matching a rule
+type Finding struct {
+	Version  uint64   `json:"version"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+}
+
+var (
+	ignoreRegex     = regexp.MustCompile(`lint:ignore\s+([\w,-]+)`)
+	ignoreFileRegex = regexp.MustCompile(`lint:ignore-file\s+([\w,-]+)`)
+
+	createTableRegex   = regexp.MustCompile(`^CREATE (?:(?:GLOBAL |LOCAL )?(?:TEMP |TEMPORARY )|UNLOGGED )?TABLE (?:IF NOT EXISTS )?([^\s(]+)`)
+	alterTableRegex    = regexp.MustCompile(`^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?([^\s]+) (.*)$`)
+	dropTableRegex     = regexp.MustCompile(`^DROP TABLE (?:IF EXISTS )?(.+?)(?: CASCADE| RESTRICT)?$`)
+	dropRegex          = regexp.MustCompile(`^DROP (?:COLUMN )?(?:IF EXISTS )?([^\s]+)`)
+	alterTypeRegex     = regexp.MustCompile(`^ALTER (?:COLUMN )?([^\s]+) (?:SET DATA )?TYPE\b`)
+	addColumnRegex     = regexp.MustCompile(`^ADD (?:COLUMN )?(?:IF NOT EXISTS )?([^\s]+)`)
+	setNotNullRegex    = regexp.MustCompile(`^ALTER (?:COLUMN )?([^\s]+) SET NOT NULL\b`)
+	createIndexRegex   = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX\b(.*?) ON (?:ONLY )?([^\s(]+)`)
+	notNullRegex       = regexp.MustCompile(`\bNOT NULL\b`)
+	defaultRegex       = regexp.MustCompile(`\bDEFAULT\b`)
+	concurrentlyRegex  = regexp.MustCompile(`^ CONCURRENTLY\b`)
+	dropNotColumnWords = map[string]bool{
+		"CONSTRAINT": true,
+		"DEFAULT":    true,
+		"NOT":        true,
+		"IDENTITY":   true,
+		"EXPRESSION": true,
+	}
+	addNotColumnWords = map[string]bool{
+		"CONSTRAINT": true,
+		"PRIMARY":    true,
+		"UNIQUE":     true,
+		"FOREIGN":    true,
+		"CHECK":      true,
+		"EXCLUDE":    true,
+	}
+)
+
+// Lint returns the findings of all rules for the migration, except the
+// suppressed ones. A rule is suppressed for a statement by a
+// "-- lint:ignore <rule>[,<rule>]" comment before or inside it, and for
+// the whole migration by a "-- lint:ignore-file <rule>" comment.
+func Lint(m Migration) []Finding {
+	statements := sqlparse.Split(string(m.Up))
+	fileIgnored := make(map[string]bool)
+	addIgnored(fileIgnored, ignoreFileRegex, string(m.Up))
+
+	findings := make([]Finding, 0)
+	add := func(rule string, ignored map[string]bool, line int, format string, args ...interface{}) {
+		if fileIgnored[rule] || fileIgnored["all"] || ignored[rule] || ignored["all"] {
+			return
+		}
+		r, _ := GetRule(rule)
+		findings = append(findings, Finding{
+			Version:  m.Version,
+			Rule:     rule,
+			Severity: r.Severity,
+			Message:  fmt.Sprintf(format, args...),
+			File:     m.UpFile,
+			Line:     fileLine(line, m.UpLine),
+		})
+	}
+
+	// tables created by this migration have no rows yet
+	created := make(map[string]bool)
+	for _, s := range statements {
+		ignored := make(map[string]bool)
+		for _, comment := range s.Comments {
+			addIgnored(ignored, ignoreRegex, comment)
+		}
+		if match := createTableRegex.FindStringSubmatch(s.Code); match != nil {
+			created[tableName(match[1])] = true
+			continue
+		}
+		if match := dropTableRegex.FindStringSubmatch(s.Code); match != nil {
+			add(RuleDropTable, ignored, s.Line, "drops table %s", strings.ToLower(match[1]))
+			continue
+		}
+		if match := createIndexRegex.FindStringSubmatch(s.Code); match != nil {
+			if !concurrentlyRegex.MatchString(match[1]) && !created[tableName(match[2])] {
+				add(RuleIndexNotConcurrent, ignored, s.Line, "creates an index on %s without CONCURRENTLY", strings.ToLower(match[2]))
+			}
+			continue
+		}
+		match := alterTableRegex.FindStringSubmatch(s.Code)
+		if match == nil {
+			continue
+		}
+		table := tableName(match[1])
+		name := strings.ToLower(match[1])
+		for _, action := range splitActions(match[2]) {
+			if col := dropRegex.FindStringSubmatch(action); col != nil && !dropNotColumnWords[col[1]] {
+				add(RuleDropColumn, ignored, s.Line, "drops column %s of %s", strings.ToLower(col[1]), name)
+			}
+			if col := alterTypeRegex.FindStringSubmatch(action); col != nil && !created[table] {
+				add(RuleAlterColumnType, ignored, s.Line, "changes the type of column %s of %s", strings.ToLower(col[1]), name)
+			}
+			if col := addColumnRegex.FindStringSubmatch(action); col != nil && !addNotColumnWords[col[1]] && !created[table] {
+				if notNullRegex.MatchString(action) && !defaultRegex.MatchString(action) {
+					add(RuleNotNullWithoutDefault, ignored, s.Line, "adds NOT NULL column %s to %s without a default", strings.ToLower(col[1]), name)
+				}
+			}
+			if col := setNotNullRegex.FindStringSubmatch(action); col != nil && !created[table] {
+				add(RuleNotNullWithoutDefault, ignored, s.Line, "sets column %s of %s NOT NULL, which fails if it has null values", strings.ToLower(col[1]), name)
+			}
+		}
+	}
+
+	if m.DownFile == "" {
+		add(RuleMissingDown, nil, 1, "migration has no down.sql")
+	} else if len(sqlparse.Split(string(m.Down))) == 0 {
+		add(RuleMissingDown, nil, 1, "down migration %s is empty", m.DownFile)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// fileLine returns the line of the file of a line of sql which starts on
+// the line start of the file
+func fileLine(line, start int) int {
+	if start < 1 {
+		return line
+	}
+	return line + start - 1
+}
+
+// addIgnored adds the rules listed in the comment to ignored
+func addIgnored(ignored map[string]bool, regex *regexp.Regexp, comment string) {
+	for _, match := range regex.FindAllStringSubmatch(comment, -1) {
+		for _, rule := range strings.Split(match[1], ",") {
+			if rule != "" {
+				ignored[rule] = true
+			}
+		}
+	}
+}
+
+// splitActions splits the actions of an ALTER TABLE statement
+// on the commas which are not inside parentheses
+func splitActions(actions string) []string {
+	var result []string
+	depth := 0
+	start := 0
+	for i, c := range actions {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(actions[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(result, strings.TrimSpace(actions[start:]))
+}
+
+// tableName returns the schema qualified name of a table, the public
+// schema is assumed for unqualified names
+func tableName(name string) string {
+	name = strings.Replace(name, `"`, "", -1)
+	if !strings.Contains(name, ".") {
+		name = "PUBLIC." + name
+	}
+	return name
+}
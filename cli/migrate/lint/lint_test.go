@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tt := []struct {
+		name   string
+		up     string
+		down   string
+		upLine int
+		expect []string
+		lines  []int
+	}{
+		{
+			name:   "drop table",
+			up:     "DROP TABLE foo;",
+			expect: []string{RuleDropTable},
+			lines:  []int{1},
+		},
+		{
+			name:   "drop column",
+			up:     "ALTER TABLE foo DROP COLUMN bar, DROP CONSTRAINT foo_pkey;",
+			expect: []string{RuleDropColumn},
+		},
+		{
+			name:   "alter column type",
+			up:     "ALTER TABLE foo ALTER COLUMN bar TYPE bigint;",
+			expect: []string{RuleAlterColumnType},
+		},
+		{
+			name:   "not null without default",
+			up:     "ALTER TABLE foo ADD COLUMN bar text NOT NULL, ADD COLUMN baz text NOT NULL DEFAULT 'x';",
+			expect: []string{RuleNotNullWithoutDefault},
+		},
+		{
+			name:   "set not null",
+			up:     "ALTER TABLE foo ALTER COLUMN bar SET NOT NULL;",
+			expect: []string{RuleNotNullWithoutDefault},
+		},
+		{
+			name:   "new table",
+			up:     "CREATE TABLE foo (id int);\nALTER TABLE foo ADD COLUMN bar text NOT NULL;\nCREATE INDEX ON foo (bar);",
+			expect: []string{},
+		},
+		{
+			name:   "create index",
+			up:     "CREATE INDEX CONCURRENTLY foo_a ON foo (a);\n\nCREATE UNIQUE INDEX foo_b ON public.foo (b);",
+			expect: []string{RuleIndexNotConcurrent},
+			lines:  []int{3},
+		},
+		{
+			name:   "missing down",
+			up:     "CREATE TABLE foo (id int);",
+			down:   "-",
+			expect: []string{RuleMissingDown},
+		},
+		{
+			name:   "empty down",
+			up:     "CREATE TABLE foo (id int);",
+			down:   "-- nothing to do\n",
+			expect: []string{RuleMissingDown},
+		},
+		{
+			name:   "strings and functions",
+			up:     "INSERT INTO logs VALUES ('DROP TABLE foo');\nCREATE FUNCTION f() RETURNS void AS $$ DROP TABLE foo; $$ LANGUAGE sql;",
+			expect: []string{},
+		},
+		{
+			name:   "suppressed statement",
+			up:     "-- lint:ignore drop-table\nDROP TABLE foo;\nDROP TABLE bar; -- lint:ignore drop-table\nDROP TABLE baz;",
+			expect: []string{RuleDropTable},
+			lines:  []int{4},
+		},
+		{
+			name:   "suppressed file",
+			up:     "DROP TABLE foo;\nALTER TABLE bar DROP COLUMN baz;\n-- lint:ignore-file drop-table,missing-down",
+			down:   "-",
+			expect: []string{RuleDropColumn},
+			lines:  []int{2},
+		},
+		{
+			name:   "section of a single file migration",
+			up:     "SELECT 1;\nDROP TABLE foo;",
+			upLine: 3,
+			expect: []string{RuleDropTable},
+			lines:  []int{4},
+		},
+	}
+
+	for _, v := range tt {
+		m := Migration{
+			Version:  1,
+			UpFile:   "1_test/up.sql",
+			Up:       []byte(v.up),
+			DownFile: "1_test/down.sql",
+			Down:     []byte("SELECT 1;"),
+			UpLine:   v.upLine,
+		}
+		if v.down == "-" {
+			m.DownFile = ""
+			m.Down = nil
+		} else if v.down != "" {
+			m.Down = []byte(v.down)
+		}
+		findings := Lint(m)
+		if len(findings) != len(v.expect) {
+			t.Errorf("%s: expected %v, got %+v", v.name, v.expect, findings)
+			continue
+		}
+		for i, f := range findings {
+			if f.Rule != v.expect[i] {
+				t.Errorf("%s: expected %s, got %s", v.name, v.expect[i], f.Rule)
+			}
+			if v.lines != nil && f.Line != v.lines[i] {
+				t.Errorf("%s: expected line %d, got %d", v.name, v.lines[i], f.Line)
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"sync"
@@ -35,6 +36,8 @@ import (
 var DefaultPrefetchMigrations = uint64(10)
 
 // DefaultLockTimeout sets the max time a database driver has to acquire a lock.
+// NewMigrate adds it to the lock timeout of the driver, so that the driver
+// times out first and reports the holder of the lock.
 var DefaultLockTimeout = 15 * time.Second
 
 var (
@@ -65,7 +68,51 @@ type ErrDirty struct {
 }
 
 func (e ErrDirty) Error() string {
-	return fmt.Sprintf("Dirty database version %v. Fix and force version.", e.Version)
+	return fmt.Sprintf("Dirty database version %v. Fix and force version with 'hasura migrate force %v', or revert the changes and run 'hasura migrate repair'.", e.Version, e.Version)
+}
+
+// ErrPartialApply is returned when migrations are applied one by one and
+// a migration fails. Applied lists the versions applied before the failure.
+type ErrPartialApply struct {
+	Applied []uint64
+	Version uint64
+	Err     error
+}
+
+func (e ErrPartialApply) Error() string {
+	failed := "migrations failed"
+	if e.Version != 0 {
+		failed = fmt.Sprintf("version %d failed", e.Version)
+	}
+	if len(e.Applied) == 0 {
+		return fmt.Sprintf("%s, no migrations were applied: %v", failed, e.Err)
+	}
+	versions := make([]string, 0, len(e.Applied))
+	for _, v := range e.Applied {
+		versions = append(versions, fmt.Sprintf("%d", v))
+	}
+	return fmt.Sprintf("%s after applying %d migration(s) (%s): %v", failed, len(e.Applied), strings.Join(versions, ", "), e.Err)
+}
+
+// Cause returns the error from the failed migration.
+func (e ErrPartialApply) Cause() error {
+	return e.Err
+}
+
+// ErrOutOfOrder is returned when local migrations which are older than the
+// last applied version are not applied on the database, e.g. after merging
+// branches which both added migrations.
+type ErrOutOfOrder struct {
+	Last     uint64
+	Versions []uint64
+}
+
+func (e ErrOutOfOrder) Error() string {
+	versions := make([]string, 0, len(e.Versions))
+	for _, v := range e.Versions {
+		versions = append(versions, fmt.Sprintf("%d", v))
+	}
+	return fmt.Sprintf("found unapplied migrations older than the last applied version %d (%s). Apply them in version order with --allow-out-of-order", e.Last, strings.Join(versions, ", "))
 }
 
 type Migrate struct {
@@ -104,6 +151,31 @@ type Migrate struct {
 
 	SkipExecution bool
 	DryRun        bool
+	// PerMigration sends and records each version separately
+	// instead of applying all of them in a single bulk query.
+	PerMigration bool
+	// ChunkSize and ChunkCount split the migrations into multiple bulk
+	// queries of at most ChunkSize bytes (estimated) or ChunkCount migrations,
+	// unless they are 0. Each chunk is applied in its own transaction.
+	ChunkSize  int64
+	ChunkCount int
+
+	// Templating renders the sql migrations as templates with the
+	// environment variables when they are sent to the database
+	Templating bool
+	// AllowOutOfOrder allows applying versions which are older
+	// than the last applied version.
+	AllowOutOfOrder bool
+	// PrintSQL prints the sql of the migrations on a dry run,
+	// as it would be sent to the server.
+	PrintSQL bool
+	// ShowPlan prints the queries of the bulk request on a dry run,
+	// PlanFile is where the bulk request is written to if set.
+	ShowPlan bool
+	PlanFile string
+
+	// plan collects the queries of the migrations on a verbose dry run
+	plan *Plan
 }
 
 // New returns a new Migrate instance from a source URL and a database URL.
@@ -204,7 +276,22 @@ func (m *Migrate) calculateStatus() (err error) {
 		return err
 	}
 
-	return m.readStatusFromDatabase()
+	err = m.readStatusFromDatabase()
+	if err != nil {
+		return err
+	}
+
+	versions, err := m.outOfOrderVersions()
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if migrStatus, ok := m.status.Read(version); ok {
+			migrStatus.IsOutOfOrder = true
+		}
+	}
+
+	return m.readRepeatableStatus()
 }
 
 func (m *Migrate) readStatusFromSource() (err error) {
@@ -278,6 +365,14 @@ func (m *Migrate) newMigrationStatus(version uint64, driverType string) *Migrati
 		migrStatus.IsPresent = true
 	case "database":
 		migrStatus.IsApplied = true
+		migrStatus.IsDirty = m.databaseDrv.IsDirty(version)
+		if migrStatus.IsPresent {
+			modified, err := m.isModified(version)
+			if err != nil {
+				m.Logger.Debugf("unable to compute checksum of version %d: %v", version, err)
+			}
+			migrStatus.IsModified = modified
+		}
 	default:
 		return nil
 	}
@@ -292,6 +387,12 @@ func (m *Migrate) GetStatus() (*Status, error) {
 	return m.status, nil
 }
 
+// GetHistory returns the versions applied or rolled back on the database,
+// oldest first
+func (m *Migrate) GetHistory() ([]database.HistoryEntry, error) {
+	return m.databaseDrv.GetHistory()
+}
+
 func (m *Migrate) GetSetting(name string) (string, error) {
 	val, err := m.databaseDrv.GetSetting(name)
 	if err != nil {
@@ -321,6 +422,14 @@ func (m *Migrate) GetUnappliedMigrations(version uint64) []uint64 {
 	return m.sourceDrv.GetUnappliedMigrations(version)
 }
 
+// ReadSQL returns the content and the file name of the up or down sql
+// migration of version. The error satisfies os.IsNotExist if the
+// migration has no such file.
+func (m *Migrate) ReadSQL(version uint64, direction source.Direction) (body []byte, fileName string, err error) {
+	body, fileName, _, err = source.ReadSQL(m.sourceDrv, version, direction)
+	return body, fileName, err
+}
+
 func (m *Migrate) GetIntroSpectionSchema() (interface{}, error) {
 	return m.databaseDrv.GetIntroSpectionSchema()
 }
@@ -333,6 +442,10 @@ func (m *Migrate) EnableCheckMetadataConsistency(enabled bool) {
 	m.databaseDrv.EnableCheckMetadataConsistency(enabled)
 }
 
+func (m *Migrate) EnableIncrementalMetadata(enabled bool) {
+	m.databaseDrv.EnableIncrementalMetadata(enabled)
+}
+
 func (m *Migrate) ExportMetadata() (map[string][]byte, error) {
 	return m.databaseDrv.ExportMetadata()
 }
@@ -385,7 +498,7 @@ func (m *Migrate) RemoveVersions(versions []uint64) error {
 	}
 
 	for _, version := range versions {
-		m.databaseDrv.RemoveVersion(int64(version))
+		m.databaseDrv.RemoveVersion(int64(version), database.KindSkip)
 	}
 	return m.unlockErr(nil)
 }
@@ -402,13 +515,14 @@ func (m *Migrate) Query(data interface{}) error {
 	return m.databaseDrv.Query(data)
 }
 
-// Squash migrations from version v into a new migration.
+// Squash migrations from version v up to and including version to into
+// a new migration. If to is 0, the migrations up to the latest one are squashed.
 // Returns a list of migrations that are squashed: vs
 // the squashed metadata for all UP steps: um
 // the squashed SQL for all UP steps: us
 // the squashed metadata for all down steps: dm
 // the squashed SQL for all down steps: ds
-func (m *Migrate) Squash(v uint64) (vs []int64, um []interface{}, us []byte, dm []interface{}, ds []byte, err error) {
+func (m *Migrate) Squash(v, to uint64) (vs []int64, um []interface{}, us []byte, dm []interface{}, ds []byte, err error) {
 	// check the migration mode on the database
 	mode, err := m.databaseDrv.GetSetting("migration_mode")
 	if err != nil {
@@ -421,17 +535,21 @@ func (m *Migrate) Squash(v uint64) (vs []int64, um []interface{}, us []byte, dm
 		return
 	}
 
+	if err = m.squashRangeExists(v, to); err != nil {
+		return
+	}
+
 	// concurrently squash all the up migrations
 	// read all up migrations from source and send each migration
 	// to the returned channel
 	retUp := make(chan interface{}, m.PrefetchMigrations)
-	go m.squashUp(v, retUp)
+	go m.squashUp(v, to, retUp)
 
 	// concurrently squash all down migrations
 	// read all down migrations from source and send each migration
 	// to the returned channel
 	retDown := make(chan interface{}, m.PrefetchMigrations)
-	go m.squashDown(v, retDown)
+	go m.squashDown(v, to, retDown)
 
 	// combine squashed up and down migrations into a single one when they're ready
 	dataUp := make(chan interface{}, m.PrefetchMigrations)
@@ -541,6 +659,10 @@ func (m *Migrate) Migrate(version uint64, direction string) error {
 		return ErrNoMigrationMode
 	}
 
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+
 	if err := m.lock(); err != nil {
 		return err
 	}
@@ -569,14 +691,22 @@ func (m *Migrate) QueryWithVersion(version uint64, data io.ReadCloser, skipExecu
 	}
 
 	if !skipExecution {
-		if err := m.databaseDrv.Run(data, "meta", ""); err != nil {
+		queryVersion := database.NilVersion
+		if version != 0 {
+			queryVersion = int64(version)
+		}
+		if err := m.databaseDrv.Run(data, "meta", "", queryVersion); err != nil {
 			m.databaseDrv.ResetQuery()
 			return m.unlockErr(err)
 		}
 	}
 
 	if version != 0 {
-		if err := m.databaseDrv.InsertVersion(int64(version)); err != nil {
+		kind := database.KindApply
+		if skipExecution {
+			kind = database.KindSkip
+		}
+		if err := m.databaseDrv.InsertVersion(int64(version), kind); err != nil {
 			m.databaseDrv.ResetQuery()
 			return m.unlockErr(err)
 		}
@@ -600,6 +730,17 @@ func (m *Migrate) Steps(n int64) error {
 		return ErrNoChange
 	}
 
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+
+	if n > 0 {
+		// readUp applies the oldest unapplied versions first
+		if err := m.checkOutOfOrder(-1); err != nil {
+			return err
+		}
+	}
+
 	if err := m.lock(); err != nil {
 		return err
 	}
@@ -631,22 +772,22 @@ func (m *Migrate) Up() error {
 		return ErrNoMigrationMode
 	}
 
-	if err := m.lock(); err != nil {
+	if err := m.checkDirty(); err != nil {
 		return err
 	}
 
-	curVersion, dirty, err := m.databaseDrv.Version()
-	if err != nil {
+	if err := m.checkOutOfOrder(-1); err != nil {
 		return err
 	}
 
-	if dirty {
-		return ErrDirty{curVersion}
+	if err := m.lock(); err != nil {
+		return err
 	}
 
 	ret := make(chan interface{}, m.PrefetchMigrations)
 
-	go m.readUp(-1, ret)
+	// repeatable migrations run after all the versions
+	go m.readUpAndRepeatables(ret)
 
 	if m.DryRun {
 		return m.unlockErr(m.runDryRun(ret))
@@ -667,10 +808,26 @@ func (m *Migrate) Down() error {
 		return ErrNoMigrationMode
 	}
 
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+
 	if err := m.lock(); err != nil {
 		return err
 	}
 
+	ret := make(chan interface{}, m.PrefetchMigrations)
+	go m.readDown(-1, ret)
+
+	if m.DryRun {
+		return m.unlockErr(m.runDryRun(ret))
+	} else {
+		return m.unlockErr(m.runMigrations(ret))
+	}
+}
+
+// checkDirty returns ErrDirty if a version is marked as dirty in the database
+func (m *Migrate) checkDirty() error {
 	curVersion, dirty, err := m.databaseDrv.Version()
 	if err != nil {
 		return err
@@ -679,18 +836,180 @@ func (m *Migrate) Down() error {
 	if dirty {
 		return ErrDirty{curVersion}
 	}
+	return nil
+}
 
-	ret := make(chan interface{}, m.PrefetchMigrations)
-	go m.readDown(-1, ret)
+// outOfOrderVersions returns the local versions which are not applied on
+// the database but are older than the last applied version
+func (m *Migrate) outOfOrderVersions() ([]uint64, error) {
+	last, ok := m.databaseDrv.Last()
+	if !ok {
+		return nil, nil
+	}
+	var versions []uint64
+	version, err := m.sourceDrv.First()
+	for err == nil && version < last {
+		if !m.databaseDrv.Read(version) {
+			versions = append(versions, version)
+		}
+		version, err = m.sourceDrv.Next(version)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return versions, nil
+}
 
-	if m.DryRun {
-		return m.unlockErr(m.runDryRun(ret))
-	} else {
-		return m.unlockErr(m.runMigrations(ret))
+// checkOutOfOrder returns ErrOutOfOrder if there are out of order versions
+// up to the version to (-1 for all of them) and AllowOutOfOrder is not set
+func (m *Migrate) checkOutOfOrder(to int64) error {
+	if m.AllowOutOfOrder {
+		return nil
 	}
+	versions, err := m.outOfOrderVersions()
+	if err != nil {
+		return err
+	}
+	var pending []uint64
+	for _, version := range versions {
+		if to == -1 || int64(version) <= to {
+			pending = append(pending, version)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	last, _ := m.databaseDrv.Last()
+	return ErrOutOfOrder{Last: last, Versions: pending}
 }
 
-func (m *Migrate) squashUp(version uint64, ret chan<- interface{}) {
+// Force marks the version as applied and clears its dirty state,
+// without running the migration.
+func (m *Migrate) Force(version uint64) error {
+	mode, err := m.databaseDrv.GetSetting("migration_mode")
+	if err != nil {
+		return err
+	}
+
+	if mode != "true" {
+		return ErrNoMigrationMode
+	}
+
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	if err := m.databaseDrv.InsertVersion(int64(version), database.KindForce); err != nil {
+		m.databaseDrv.ResetQuery()
+		return m.unlockErr(err)
+	}
+	if err := m.saveChecksum(version); err != nil {
+		m.databaseDrv.ResetQuery()
+		return m.unlockErr(err)
+	}
+	return m.unlockErr(nil)
+}
+
+// Baseline marks the versions as applied without running them, along with
+// the checksums of their local files
+func (m *Migrate) Baseline(versions []uint64) error {
+	mode, err := m.databaseDrv.GetSetting("migration_mode")
+	if err != nil {
+		return err
+	}
+
+	if mode != "true" {
+		return ErrNoMigrationMode
+	}
+
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		if err := m.databaseDrv.InsertVersion(int64(version), database.KindBaseline); err != nil {
+			m.databaseDrv.ResetQuery()
+			return m.unlockErr(err)
+		}
+		if err := m.saveChecksum(version); err != nil {
+			m.databaseDrv.ResetQuery()
+			return m.unlockErr(err)
+		}
+	}
+	return m.unlockErr(nil)
+}
+
+// MoveState moves the migrations state from the location from to the one
+// configured for the database driver
+func (m *Migrate) MoveState(from database.StateLocation) error {
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	if err := m.databaseDrv.MoveState(from); err != nil {
+		m.databaseDrv.ResetQuery()
+		return m.unlockErr(err)
+	}
+	return m.unlockErr(nil)
+}
+
+// Repair removes the versions marked as dirty from the database, so that
+// they are considered not applied and can be applied again.
+// It returns the versions which were removed.
+func (m *Migrate) Repair() ([]uint64, error) {
+	mode, err := m.databaseDrv.GetSetting("migration_mode")
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != "true" {
+		return nil, ErrNoMigrationMode
+	}
+
+	status, err := m.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	var versions []uint64
+	for _, version := range status.Index {
+		if status.Migrations[version].IsDirty {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoChange
+	}
+
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		if err := m.databaseDrv.RemoveVersion(int64(version), database.KindRepair); err != nil {
+			m.databaseDrv.ResetQuery()
+			return nil, m.unlockErr(err)
+		}
+	}
+	return versions, m.unlockErr(nil)
+}
+
+// squashRangeExists checks that the versions from and to (if not 0)
+// exist in the source and form a valid range
+func (m *Migrate) squashRangeExists(from, to uint64) error {
+	if to == 0 {
+		return nil
+	}
+	if to < from {
+		return fmt.Errorf("version %d to squash to is before version %d to squash from", to, from)
+	}
+	directions := m.sourceDrv.GetDirections(to)
+	if !directions[source.Up] && !directions[source.Down] && !directions[source.MetaUp] && !directions[source.MetaDown] {
+		return fmt.Errorf("version %d to squash to not found", to)
+	}
+	return nil
+}
+
+func (m *Migrate) squashUp(version, to uint64, ret chan<- interface{}) {
 	defer close(ret)
 	currentVersion := version
 	count := int64(0)
@@ -758,6 +1077,11 @@ func (m *Migrate) squashUp(version uint64, ret chan<- interface{}) {
 			return
 		}
 
+		// the range to squash ends at "--to" version
+		if to != 0 && next > to {
+			return
+		}
+
 		// Check if next files exists (yaml or sql)
 		if err = m.versionUpExists(next); err != nil {
 			ret <- err
@@ -786,14 +1110,19 @@ func (m *Migrate) squashUp(version uint64, ret chan<- interface{}) {
 	}
 }
 
-func (m *Migrate) squashDown(version uint64, ret chan<- interface{}) {
+func (m *Migrate) squashDown(version, to uint64, ret chan<- interface{}) {
 	defer close(ret)
 
 	// get the last version from the source driver
-	from, err := m.sourceDrv.GetLocalVersion()
-	if err != nil {
-		ret <- err
-		return
+	// unless the range to squash ends at "--to" version
+	from := to
+	if from == 0 {
+		var err error
+		from, err = m.sourceDrv.GetLocalVersion()
+		if err != nil {
+			ret <- err
+			return
+		}
 	}
 
 	for {
@@ -805,7 +1134,7 @@ func (m *Migrate) squashDown(version uint64, ret chan<- interface{}) {
 			return
 		}
 
-		err = m.versionDownExists(from)
+		err := m.versionDownExists(from)
 		if err != nil {
 			ret <- err
 			return
@@ -1187,49 +1516,162 @@ func (m *Migrate) readDown(limit int64, ret chan<- interface{}) {
 // to stop execution because it might have received a stop signal on the
 // GracefulStop channel.
 func (m *Migrate) runMigrations(ret <-chan interface{}) error {
-	var lastInsertVersion int64
+	// how the versions are recorded in the history
+	kind := database.KindApply
+	if m.SkipExecution {
+		kind = database.KindSkip
+	}
+	// version whose files are queued and which is yet to be recorded, it is
+	// recorded after its last file so that all of them are timed with it
+	var unrecorded *Migration
+	record := func() error {
+		if unrecorded == nil {
+			return nil
+		}
+		migr := unrecorded
+		unrecorded = nil
+		version := int64(migr.Version)
+		if version != migr.TargetVersion {
+			// Delete Version number from the table
+			return m.databaseDrv.RemoveVersion(version, kind)
+		}
+		// Insert Version number into the table
+		if err := m.databaseDrv.InsertVersion(version, kind); err != nil {
+			return err
+		}
+		return m.saveChecksum(migr.Version)
+	}
+	// version whose migrations are yet to be flushed, when applying per migration
+	var pendingVersion *Migration
+	var applied []uint64
+	chunks := &chunker{m: m}
+	flush := func() error {
+		if pendingVersion == nil {
+			return nil
+		}
+		if err := m.databaseDrv.Flush(); err != nil {
+			return ErrPartialApply{Applied: applied, Version: pendingVersion.Version, Err: err}
+		}
+		applied = append(applied, pendingVersion.Version)
+		direction := "up"
+		if int64(pendingVersion.Version) != pendingVersion.TargetVersion {
+			direction = "down"
+		}
+		m.Logger.Infof("applied %s migration %d_%s", direction, pendingVersion.Version, pendingVersion.Identifier)
+		pendingVersion = nil
+		return nil
+	}
+	// fail clears the pending queries and reports the versions applied so far along with err
+	fail := func(err error) error {
+		m.databaseDrv.ResetQuery()
+		if _, ok := err.(ErrPartialApply); ok {
+			return err
+		}
+		if (m.PerMigration || chunks.sent > 0) && len(applied) > 0 {
+			return ErrPartialApply{Applied: applied, Err: err}
+		}
+		return err
+	}
 	for r := range ret {
 		if m.stop() {
+			if err := record(); err != nil {
+				return fail(err)
+			}
 			return nil
 		}
 
 		switch r.(type) {
 		case error:
-			// Clear Migration query
-			m.databaseDrv.ResetQuery()
-			return r.(error)
+			return fail(r.(error))
 		case *Migration:
 			migr := r.(*Migration)
-			if migr.Body != nil {
+			if unrecorded != nil && (migr.Repeatable != "" || migr.Version != unrecorded.Version) {
+				if err := record(); err != nil {
+					return fail(err)
+				}
+			}
+			// the chunk is completed before the plan starts the step of migr
+			if chunks.enabled() && (migr.Body != nil || migr.Repeatable != "") {
+				if err := chunks.next(migr, &applied); err != nil {
+					return fail(err)
+				}
+			}
+			if m.plan != nil && (migr.Body != nil || migr.Repeatable != "") {
+				m.plan.next(migr, m.databaseDrv.Queries())
+			}
+			if migr.Repeatable != "" {
+				// versions pending when applying per migration go first
+				if err := flush(); err != nil {
+					return err
+				}
 				if !m.SkipExecution {
-					if err := m.databaseDrv.Run(migr.BufferedBody, migr.FileType, migr.FileName); err != nil {
-						return err
+					body, err := m.sqlBody(migr)
+					if err != nil {
+						return fail(err)
+					}
+					if err := m.databaseDrv.Run(body, migr.FileType, migr.FileName, database.NilVersion); err != nil {
+						return fail(err)
 					}
 				}
-
-				version := int64(migr.Version)
-				if version == migr.TargetVersion {
-					if version != lastInsertVersion {
-						// Insert Version number into the table
-						if err := m.databaseDrv.InsertVersion(version); err != nil {
-							return err
-						}
-						lastInsertVersion = version
+				if err := m.databaseDrv.SetRepeatableChecksum(migr.Repeatable, migr.Checksum); err != nil {
+					return fail(err)
+				}
+				if m.PerMigration {
+					if err := m.databaseDrv.Flush(); err != nil {
+						return fail(err)
 					}
-				} else {
-					// Delete Version number from the table
-					if err := m.databaseDrv.RemoveVersion(version); err != nil {
-						return err
+					m.Logger.Infof("applied repeatable migration %s", migr.Repeatable)
+				}
+				continue
+			}
+			if m.PerMigration && pendingVersion != nil && pendingVersion.Version != migr.Version {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			if migr.Body != nil {
+				if m.PerMigration && pendingVersion == nil {
+					// the version stays dirty if its migrations fail
+					if err := m.databaseDrv.SetDirty(int64(migr.Version)); err != nil {
+						return fail(err)
+					}
+					if err := m.databaseDrv.Flush(); err != nil {
+						return fail(err)
+					}
+					pendingVersion = migr
+				}
+				if !m.SkipExecution {
+					body, err := m.sqlBody(migr)
+					if err != nil {
+						return fail(err)
+					}
+					if err := m.databaseDrv.Run(body, migr.FileType, migr.FileName, int64(migr.Version)); err != nil {
+						return fail(err)
 					}
 				}
+				unrecorded = migr
 			}
 		}
 	}
+	if err := record(); err != nil {
+		return fail(err)
+	}
+	if m.PerMigration {
+		return flush()
+	}
+	if chunks.sent > 0 {
+		// report the last chunk along with the ones sent before
+		return chunks.flush(&applied)
+	}
 	return nil
 }
 
 func (m *Migrate) runDryRun(ret <-chan interface{}) error {
+	if m.ShowPlan || m.PlanFile != "" {
+		return m.runPlan(ret)
+	}
 	migrations := make([]*Migration, 0)
+	sqls := &bytes.Buffer{}
 	var lastInsertVersion int64
 	for r := range ret {
 		if m.stop() {
@@ -1241,6 +1683,19 @@ func (m *Migrate) runDryRun(ret <-chan interface{}) error {
 			return r.(error)
 		case *Migration:
 			migr := r.(*Migration)
+			if m.PrintSQL && migr.FileType == "sql" && migr.Body != nil {
+				body, err := m.sqlBody(migr)
+				if err != nil {
+					return err
+				}
+				if err := printDryRunSQL(sqls, migr.FileName, body); err != nil {
+					return err
+				}
+			}
+			if migr.Repeatable != "" {
+				migrations = append(migrations, migr)
+				continue
+			}
 			if migr.Body != nil {
 				version := int64(migr.Version)
 				if version != lastInsertVersion {
@@ -1251,6 +1706,23 @@ func (m *Migrate) runDryRun(ret <-chan interface{}) error {
 		}
 	}
 	fmt.Fprintf(os.Stdout, "%s", printDryRunStatus(migrations))
+	if sqls.Len() > 0 {
+		fmt.Fprintf(os.Stdout, "\n%s", sqls)
+	}
+	return nil
+}
+
+// printDryRunSQL writes the sql of the migration file to w
+func printDryRunSQL(w io.Writer, fileName string, r io.Reader) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", fileName)
+	}
+	fmt.Fprintf(w, "-- %s\n%s", fileName, body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
 	return nil
 }
 
@@ -1433,6 +1905,7 @@ func (m *Migrate) newMigration(version uint64, targetVersion int64) (*Migration,
 			if err != nil {
 				return nil, err
 			}
+			migr.Line = source.Line(r)
 		}
 
 	} else {
@@ -1453,6 +1926,7 @@ func (m *Migrate) newMigration(version uint64, targetVersion int64) (*Migration,
 			if err != nil {
 				return nil, err
 			}
+			migr.Line = source.Line(r)
 		}
 	}
 
@@ -1548,45 +2022,31 @@ func (m *Migrate) lock() error {
 		return ErrLocked
 	}
 
-	// create done channel, used in the timeout goroutine
-	done := make(chan bool, 1)
-	defer func() {
-		done <- true
-	}()
-
-	// use errchan to signal error back to this context
-	errchan := make(chan error, 2)
-
-	// start timeout goroutine
-	timeout := time.After(m.LockTimeout)
+	// try to acquire the lock
+	lockErr := make(chan error, 1)
 	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			case <-timeout:
-				errchan <- ErrLockTimeout
-				return
-			}
-		}
+		lockErr <- m.databaseDrv.Lock()
 	}()
 
-	// now try to acquire the lock
-	go func() {
-		if err := m.databaseDrv.Lock(); err != nil {
-			errchan <- err
-		} else {
-			errchan <- nil
+	// wait until we either time out or receive the error from Lock
+	select {
+	case err := <-lockErr:
+		if err == nil {
+			m.isLocked = true
 		}
-		return
-	}()
-
-	// wait until we either recieve ErrLockTimeout or error from Lock operation
-	err := <-errchan
-	if err == nil {
-		m.isLocked = true
+		return err
+	case <-time.After(m.LockTimeout):
+		// the lock may still be acquired after the timeout, release it so
+		// that it is not left behind
+		go func() {
+			if err := <-lockErr; err == nil {
+				if err := m.databaseDrv.UnLock(); err != nil {
+					m.Logger.Debugf("releasing the lock acquired after the timeout failed: %v", err)
+				}
+			}
+		}()
+		return ErrLockTimeout
 	}
-	return err
 }
 
 // unlock is a thread safe helper function to unlock the database.
@@ -1606,6 +2066,12 @@ func (m *Migrate) unlock() error {
 	return nil
 }
 
+// ForceUnLock releases the lock of the database held by any process, and
+// returns the holder of the released lock
+func (m *Migrate) ForceUnLock() (string, error) {
+	return m.databaseDrv.ForceUnLock()
+}
+
 // unlockErr calls unlock and returns a combined error
 // if a prevErr is not nil.
 func (m *Migrate) unlockErr(prevErr error) error {
@@ -1639,6 +2105,12 @@ func (m *Migrate) GotoVersion(gotoVersion int64) error {
 		return ErrDirty{currVersion}
 	}
 
+	if currVersion <= gotoVersion {
+		if err := m.checkOutOfOrder(gotoVersion); err != nil {
+			return err
+		}
+	}
+
 	if err := m.lock(); err != nil {
 		return err
 	}
@@ -1876,6 +2348,10 @@ func printDryRunStatus(migrations []*Migration) *bytes.Buffer {
 	w := util.NewPrefixWriter(out)
 	w.Write(util.LEVEL_0, "VERSION\tTYPE\tNAME\n")
 	for _, migration := range migrations {
+		if migration.Repeatable != "" {
+			w.Write(util.LEVEL_0, "-\trepeatable\t%s\n", migration.Repeatable)
+			continue
+		}
 		var direction string
 		if int64(migration.Version) == migration.TargetVersion {
 			direction = "up"
@@ -0,0 +1,304 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate/database"
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+	"github.com/hasura/graphql-engine/cli/migrate/source/stub"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// queueTestDriver records the queries queued by runMigrations
+type queueTestDriver struct {
+	database.Driver
+	queries []interface{}
+}
+
+func (d *queueTestDriver) Run(migration io.Reader, fileType, fileName string, version int64) error {
+	d.queries = append(d.queries, fmt.Sprintf("run %s", fileName))
+	return nil
+}
+
+func (d *queueTestDriver) InsertVersion(version int64, kind string) error {
+	d.queries = append(d.queries, fmt.Sprintf("insert %d", version))
+	return nil
+}
+
+func (d *queueTestDriver) RemoveVersion(version int64, kind string) error {
+	d.queries = append(d.queries, fmt.Sprintf("remove %d", version))
+	return nil
+}
+
+func (d *queueTestDriver) Queries() []interface{} {
+	return d.queries
+}
+
+func (d *queueTestDriver) ResetQuery() {
+	d.queries = nil
+}
+
+// noChecksumSource has no up files, so that no checksum is saved
+type noChecksumSource struct {
+	source.Driver
+}
+
+func (s *noChecksumSource) GetDirections(version uint64) map[source.Direction]bool {
+	return nil
+}
+
+func TestRunMigrationsRecordsVersionAfterItsFiles(t *testing.T) {
+	migration := func(version uint64, targetVersion int64, fileName string) *Migration {
+		return &Migration{
+			Version:       version,
+			TargetVersion: targetVersion,
+			FileName:      fileName,
+			FileType:      "sql",
+			Body:          ioutil.NopCloser(strings.NewReader("x")),
+			BufferedBody:  strings.NewReader("x"),
+		}
+	}
+	tests := []struct {
+		name       string
+		migrations []*Migration
+		expected   []interface{}
+	}{
+		{
+			"up",
+			[]*Migration{
+				migration(1, 1, "1_init.up.sql"),
+				migration(1, 1, "1_init.up.yaml"),
+				migration(2, 2, "2_posts.up.sql"),
+			},
+			[]interface{}{"run 1_init.up.sql", "run 1_init.up.yaml", "insert 1", "run 2_posts.up.sql", "insert 2"},
+		},
+		{
+			"down",
+			[]*Migration{
+				migration(2, 1, "2_posts.down.yaml"),
+				migration(2, 1, "2_posts.down.sql"),
+				migration(1, -1, "1_init.down.sql"),
+			},
+			[]interface{}{"run 2_posts.down.yaml", "run 2_posts.down.sql", "remove 2", "run 1_init.down.sql", "remove 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := test.NewNullLogger()
+			drv := &queueTestDriver{}
+			m := &Migrate{databaseDrv: drv, sourceDrv: &noChecksumSource{}, Logger: logger, GracefulStop: make(chan bool, 1)}
+			ret := make(chan interface{}, len(tt.migrations))
+			for _, migr := range tt.migrations {
+				ret <- migr
+			}
+			close(ret)
+			if err := m.runMigrations(ret); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(drv.queries, tt.expected) {
+				t.Errorf("expected queries %v, got %v", tt.expected, drv.queries)
+			}
+		})
+	}
+}
+
+// repeatableTestSource has local repeatable migrations
+type repeatableTestSource struct {
+	source.Driver
+	names []string
+}
+
+func (s *repeatableTestSource) Repeatables() []string {
+	return s.names
+}
+
+func (s *repeatableTestSource) ReadRepeatable(name string) (io.ReadCloser, string, error) {
+	return ioutil.NopCloser(strings.NewReader(name)), name + ".sql", nil
+}
+
+// repeatableTestDriver has applied repeatable migrations
+type repeatableTestDriver struct {
+	database.Driver
+	checksums map[string]string
+}
+
+func (d *repeatableTestDriver) RepeatableChecksums() map[string]string {
+	return d.checksums
+}
+
+func TestReadRepeatableStatusSortsByName(t *testing.T) {
+	m := &Migrate{
+		sourceDrv: &repeatableTestSource{names: []string{"b_views", "d_functions"}},
+		databaseDrv: &repeatableTestDriver{checksums: map[string]string{
+			"e_dropped": "1", "a_dropped": "2", "c_dropped": "3", "b_views": "4",
+		}},
+		status: NewStatus(),
+	}
+	if err := m.readRepeatableStatus(); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range m.status.Repeatables {
+		names = append(names, r.Name)
+	}
+	expected := []string{"a_dropped", "b_views", "c_dropped", "d_functions", "e_dropped"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+// newStubSource returns a source with an up migration for each version
+func newStubSource(t *testing.T, versions ...uint64) source.Driver {
+	s := &stub.Stub{Migrations: source.NewMigrations()}
+	for _, version := range versions {
+		if err := s.Migrations.Append(&source.Migration{Version: version, Identifier: fmt.Sprintf("CREATE TABLE t%d ();", version), Direction: source.Up}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// versionTestDriver has applied versions and records the versions and
+// checksums which are saved
+type versionTestDriver struct {
+	database.Driver
+	applied   []uint64
+	migration string
+	inserted  []string
+	checksums map[int64]string
+	unlocked  bool
+}
+
+func (d *versionTestDriver) GetSetting(name string) (string, error) {
+	return d.migration, nil
+}
+
+func (d *versionTestDriver) Lock() error {
+	return nil
+}
+
+func (d *versionTestDriver) UnLock() error {
+	d.unlocked = true
+	return nil
+}
+
+func (d *versionTestDriver) InsertVersion(version int64, kind string) error {
+	d.inserted = append(d.inserted, fmt.Sprintf("%d %s", version, kind))
+	return nil
+}
+
+func (d *versionTestDriver) SetChecksum(version int64, checksum string) error {
+	if d.checksums == nil {
+		d.checksums = make(map[int64]string)
+	}
+	d.checksums[version] = checksum
+	return nil
+}
+
+func (d *versionTestDriver) Last() (uint64, bool) {
+	if len(d.applied) == 0 {
+		return 0, false
+	}
+	return d.applied[len(d.applied)-1], true
+}
+
+func (d *versionTestDriver) Read(version uint64) bool {
+	for _, v := range d.applied {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckOutOfOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		local           []uint64
+		applied         []uint64
+		to              int64
+		allowOutOfOrder bool
+		expected        []uint64
+	}{
+		{"in order", []uint64{1, 2, 3}, []uint64{1, 2}, -1, false, nil},
+		{"nothing applied", []uint64{1, 2, 3}, nil, -1, false, nil},
+		{"older than the last applied", []uint64{1, 2, 3, 4, 5}, []uint64{1, 3, 4}, -1, false, []uint64{2}},
+		{"many older than the last applied", []uint64{1, 2, 3, 4, 5}, []uint64{4}, -1, false, []uint64{1, 2, 3}},
+		{"up to a version", []uint64{1, 2, 3, 4, 5}, []uint64{4}, 2, false, []uint64{1, 2}},
+		{"up to a version before them", []uint64{2, 3, 4}, []uint64{4}, 1, false, nil},
+		{"allowed", []uint64{1, 2, 3}, []uint64{3}, -1, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Migrate{
+				sourceDrv:       newStubSource(t, tt.local...),
+				databaseDrv:     &versionTestDriver{applied: tt.applied},
+				AllowOutOfOrder: tt.allowOutOfOrder,
+			}
+			err := m.checkOutOfOrder(tt.to)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			e, ok := err.(ErrOutOfOrder)
+			if !ok {
+				t.Fatalf("expected ErrOutOfOrder, got %v", err)
+			}
+			if !reflect.DeepEqual(e.Versions, tt.expected) {
+				t.Errorf("expected out of order versions %v, got %v", tt.expected, e.Versions)
+			}
+			if last := tt.applied[len(tt.applied)-1]; e.Last != last {
+				t.Errorf("expected last applied version %d, got %d", last, e.Last)
+			}
+		})
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	drv := &versionTestDriver{migration: "true"}
+	m := &Migrate{
+		sourceDrv:   newStubSource(t, 1, 2, 3),
+		databaseDrv: drv,
+		isLockedMu:  &sync.Mutex{},
+		LockTimeout: DefaultLockTimeout,
+	}
+	if err := m.Baseline([]uint64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1 baseline", "2 baseline"}
+	if !reflect.DeepEqual(drv.inserted, expected) {
+		t.Errorf("expected versions %v to be recorded, got %v", expected, drv.inserted)
+	}
+	for _, version := range []uint64{1, 2} {
+		checksum, err := m.checksum(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if drv.checksums[int64(version)] != checksum {
+			t.Errorf("expected checksum %s for version %d, got %s", checksum, version, drv.checksums[int64(version)])
+		}
+	}
+	if _, ok := drv.checksums[3]; ok {
+		t.Error("expected no checksum for version 3")
+	}
+	if !drv.unlocked {
+		t.Error("expected the lock to be released")
+	}
+
+	drv = &versionTestDriver{migration: "false"}
+	m.databaseDrv = drv
+	if err := m.Baseline([]uint64{1}); err != ErrNoMigrationMode {
+		t.Errorf("expected ErrNoMigrationMode, got %v", err)
+	}
+	if len(drv.inserted) != 0 {
+		t.Errorf("expected nothing to be recorded without migration mode, got %v", drv.inserted)
+	}
+}
@@ -26,12 +26,23 @@ type Migration struct {
 	// Can be -1, implying that this is a NilVersion.
 	TargetVersion int64
 
+	// Repeatable is the name of a repeatable migration, in which
+	// case Version and TargetVersion are not used.
+	Repeatable string
+
+	// Checksum of a repeatable migration
+	Checksum string
+
 	// File Type
 	FileType string
 
 	// File name
 	FileName string
 
+	// Line is the line of the file the body starts on, which is not the
+	// first one for a section of a single file migration
+	Line int
+
 	// Body holds an io.ReadCloser to the source.
 	Body io.ReadCloser
 
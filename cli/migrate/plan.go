@@ -0,0 +1,177 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// PlanStep holds the queries a migration adds to the bulk request,
+// including the bookkeeping of the migrations state
+type PlanStep struct {
+	Version    uint64 `json:"version,omitempty"`
+	Name       string `json:"name"`
+	Direction  string `json:"direction"`
+	Repeatable bool   `json:"repeatable,omitempty"`
+	// Request is the number of the bulk request the step is sent in,
+	// starting at 1
+	Request int           `json:"request"`
+	Queries []interface{} `json:"queries"`
+}
+
+// Plan is the bulk request which would be sent to the server, or the bulk
+// requests when the migrations are split into chunks
+type Plan struct {
+	Steps []*PlanStep `json:"steps"`
+	// Payload is the bulk request, or the list of bulk requests when there
+	// is more than one
+	Payload interface{} `json:"payload"`
+
+	// bulk requests completed so far
+	requests []interface{}
+	// number of queries assigned to the steps
+	queued int
+}
+
+// next assigns the queries added so far to the current step and starts a
+// new step for migr, unless it belongs to the current one
+func (p *Plan) next(migr *Migration, queries []interface{}) {
+	p.record(queries)
+	direction := "up"
+	if migr.Repeatable == "" && int64(migr.Version) != migr.TargetVersion {
+		direction = "down"
+	}
+	if len(p.Steps) > 0 && migr.Repeatable == "" {
+		last := p.Steps[len(p.Steps)-1]
+		if !last.Repeatable && last.Version == migr.Version && last.Direction == direction {
+			return
+		}
+	}
+	step := &PlanStep{
+		Version:   migr.Version,
+		Name:      migr.Identifier,
+		Direction: direction,
+		Request:   len(p.requests) + 1,
+		Queries:   make([]interface{}, 0),
+	}
+	if migr.Repeatable != "" {
+		step.Version = 0
+		step.Name = migr.Repeatable
+		step.Repeatable = true
+	}
+	p.Steps = append(p.Steps, step)
+}
+
+// record assigns the queries which are not part of a step yet to the current step
+func (p *Plan) record(queries []interface{}) {
+	if len(p.Steps) > 0 && len(queries) > p.queued {
+		last := p.Steps[len(p.Steps)-1]
+		last.Queries = append(last.Queries, queries[p.queued:]...)
+	}
+	p.queued = len(queries)
+}
+
+// flush completes the current bulk request with the queries added so far,
+// the queries of the next request start again at 0
+func (p *Plan) flush(queries []interface{}) {
+	p.record(queries)
+	p.requests = append(p.requests, map[string]interface{}{
+		"type": "bulk",
+		"args": queries,
+	})
+	p.queued = 0
+}
+
+// runPlan queues the migrations like runMigrations, and prints the queries
+// instead of sending them to the server
+func (m *Migrate) runPlan(ret <-chan interface{}) error {
+	// never send the queued queries on unlock
+	defer m.databaseDrv.ResetQuery()
+
+	if m.PerMigration {
+		m.Logger.Warn("the plan shows a single bulk request, applying per migration sends each version separately")
+		m.PerMigration = false
+		defer func() { m.PerMigration = true }()
+	}
+
+	m.plan = &Plan{
+		Steps: make([]*PlanStep, 0),
+	}
+	defer func() { m.plan = nil }()
+	if err := m.runMigrations(ret); err != nil {
+		return err
+	}
+	if queries := m.databaseDrv.Queries(); len(queries) > 0 || len(m.plan.requests) == 0 {
+		m.plan.flush(queries)
+	}
+	m.plan.Payload = m.plan.requests[0]
+	if len(m.plan.requests) > 1 {
+		m.plan.Payload = m.plan.requests
+	}
+
+	out, err := printPlan(m.plan)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%s", out)
+
+	if m.PlanFile != "" {
+		data, err := json.MarshalIndent(m.plan.Payload, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "cannot marshal bulk request")
+		}
+		if err := ioutil.WriteFile(m.PlanFile, append(data, '\n'), 0644); err != nil {
+			return errors.Wrap(err, "cannot write bulk request")
+		}
+		if len(m.plan.requests) > 1 {
+			m.Logger.Infof("%d bulk requests written to %s", len(m.plan.requests), m.PlanFile)
+		} else {
+			m.Logger.Infof("bulk request written to %s", m.PlanFile)
+		}
+	}
+	return nil
+}
+
+// printPlan prints the sql of run_sql queries and the other queries as json,
+// grouped by the migration they belong to
+func printPlan(plan *Plan) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+	request := 0
+	for _, step := range plan.Steps {
+		if len(plan.requests) > 1 && step.Request != request {
+			request = step.Request
+			fmt.Fprintf(buf, "-- bulk request %d of %d\n\n", request, len(plan.requests))
+		}
+		if step.Repeatable {
+			fmt.Fprintf(buf, "-- repeatable %s\n", step.Name)
+		} else {
+			fmt.Fprintf(buf, "-- %d_%s %s\n", step.Version, step.Name, step.Direction)
+		}
+		for _, query := range step.Queries {
+			data, err := json.Marshal(query)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot marshal query")
+			}
+			var q struct {
+				Type string `json:"type"`
+				Args struct {
+					SQL string `json:"sql"`
+				} `json:"args"`
+			}
+			if err := json.Unmarshal(data, &q); err == nil && q.Type == "run_sql" {
+				buf.WriteString(q.Args.SQL)
+				if len(q.Args.SQL) > 0 && q.Args.SQL[len(q.Args.SQL)-1] != '\n' {
+					buf.WriteString("\n")
+				}
+				continue
+			}
+			fmt.Fprintf(buf, "-- %s\n", data)
+		}
+		buf.WriteString("\n")
+	}
+	return buf, nil
+}
@@ -0,0 +1,155 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// RepeatableStatus is the status of a repeatable migration
+type RepeatableStatus struct {
+	// Name identifies the repeatable migration in the source folder
+	Name string `json:"name"`
+
+	// Check if the migration is present on the local.
+	IsPresent bool `json:"source_status"`
+
+	// Check if the migration was applied on the cluster.
+	IsApplied bool `json:"database_status"`
+
+	// Checksum of the local file
+	Checksum string `json:"checksum"`
+
+	// Checksum of the file when it was last applied
+	AppliedChecksum string `json:"applied_checksum"`
+}
+
+// IsPending returns true if the repeatable migration is going to be applied
+func (r *RepeatableStatus) IsPending() bool {
+	return r.IsPresent && r.Checksum != r.AppliedChecksum
+}
+
+// readRepeatable returns the body of a repeatable migration and its checksum
+func (m *Migrate) readRepeatable(name string) (body []byte, fileName string, checksum string, err error) {
+	r, fileName, err := m.sourceDrv.ReadRepeatable(name)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer r.Close()
+	body, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", "", err
+	}
+	sum := sha256.Sum256(body)
+	return body, fileName, hex.EncodeToString(sum[:]), nil
+}
+
+// readRepeatables writes the repeatable migrations which changed since they
+// were last applied to the ret channel and returns their number
+func (m *Migrate) readRepeatables(ret chan<- interface{}) (int, error) {
+	applied := m.databaseDrv.RepeatableChecksums()
+	count := 0
+	for _, name := range m.sourceDrv.Repeatables() {
+		if m.stop() {
+			return count, nil
+		}
+
+		body, fileName, checksum, err := m.readRepeatable(name)
+		if err != nil {
+			return count, err
+		}
+		if applied[name] == checksum {
+			continue
+		}
+
+		migr, err := NewMigration(ioutil.NopCloser(bytes.NewReader(body)), name, 0, 0, "sql", fileName)
+		if err != nil {
+			return count, err
+		}
+		migr.Repeatable = name
+		migr.Checksum = checksum
+		ret <- migr
+		go migr.Buffer()
+		count++
+	}
+	return count, nil
+}
+
+// readUpAndRepeatables reads all up migrations followed by the repeatable
+// migrations which changed since they were last applied.
+// Once it is done reading it will close the ret channel.
+func (m *Migrate) readUpAndRepeatables(ret chan<- interface{}) {
+	defer close(ret)
+
+	up := make(chan interface{}, m.PrefetchMigrations)
+	go m.readUp(-1, up)
+
+	noChange := false
+	for r := range up {
+		if r == ErrNoChange {
+			noChange = true
+			continue
+		}
+		if e, ok := r.(*os.PathError); ok && e.Op == "first" {
+			// there are no versions
+			noChange = true
+			continue
+		}
+		ret <- r
+		if _, ok := r.(error); ok {
+			// let readUp finish
+			go func() {
+				for range up {
+				}
+			}()
+			return
+		}
+	}
+
+	count, err := m.readRepeatables(ret)
+	if err != nil {
+		ret <- err
+		return
+	}
+	if noChange && count == 0 {
+		ret <- ErrNoChange
+	}
+}
+
+// readRepeatableStatus adds the status of the repeatable migrations
+func (m *Migrate) readRepeatableStatus() error {
+	applied := m.databaseDrv.RepeatableChecksums()
+	seen := make(map[string]bool)
+	for _, name := range m.sourceDrv.Repeatables() {
+		_, _, checksum, err := m.readRepeatable(name)
+		if err != nil {
+			return err
+		}
+		appliedChecksum, ok := applied[name]
+		m.status.Repeatables = append(m.status.Repeatables, &RepeatableStatus{
+			Name:            name,
+			IsPresent:       true,
+			IsApplied:       ok,
+			Checksum:        checksum,
+			AppliedChecksum: appliedChecksum,
+		})
+		seen[name] = true
+	}
+	for name, appliedChecksum := range applied {
+		if seen[name] {
+			continue
+		}
+		m.status.Repeatables = append(m.status.Repeatables, &RepeatableStatus{
+			Name:            name,
+			IsApplied:       true,
+			AppliedChecksum: appliedChecksum,
+		})
+	}
+	sort.Slice(m.status.Repeatables, func(i, j int) bool {
+		return m.status.Repeatables[i].Name < m.status.Repeatables[j].Name
+	})
+	return nil
+}
@@ -3,9 +3,11 @@ package source
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	nurl "net/url"
 	"sync"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -97,6 +99,15 @@ type Driver interface {
 	// Do not start reading, just return the ReadCloser!
 	ReadMetaDown(version uint64) (r io.ReadCloser, identifier string, fileName string, err error)
 
+	// Repeatables returns the names of the repeatable migrations, in the
+	// order in which they have to be applied.
+	Repeatables() []string
+
+	// ReadRepeatable returns the body of the repeatable migration with the
+	// given name and the name of its file.
+	// If there is no such migration, it must return os.ErrNotExist.
+	ReadRepeatable(name string) (r io.ReadCloser, fileName string, err error)
+
 	// ReadName returns an name that helps
 	// finding this migration in the source for a given version
 	ReadName(version uint64) (name string)
@@ -142,3 +153,23 @@ func Register(name string, driver Driver) {
 	}
 	drivers[name] = driver
 }
+
+// ReadSQL returns the content and the file name of the up or down sql
+// migration of version, and the line of the file the content starts on.
+// The error satisfies os.IsNotExist if the migration has no such file.
+func ReadSQL(drv Driver, version uint64, direction Direction) (body []byte, fileName string, line int, err error) {
+	read := drv.ReadUp
+	if direction == Down {
+		read = drv.ReadDown
+	}
+	r, _, fileName, err := read(version)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	defer r.Close()
+	body, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", 0, errors.Wrapf(err, "cannot read %s", fileName)
+	}
+	return body, fileName, Line(r), nil
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -105,47 +106,92 @@ func (f *File) Scan() error {
 					continue
 				}
 				fileName := fmt.Sprintf("%s.%s", dirName, fi.Name())
-				m, err := f.defaultParser(fileName)
+				m, err := f.parse(fileName)
 				if err != nil {
 					continue // ignore files that we can't parse
 				}
 				m.Raw = filepath.Join(dirName, fi.Name())
 				m.IsDir = true
-				ok, err := source.IsEmptyFile(m, f.path)
-				if err != nil {
-					return err
-				}
-				if !ok {
-					continue
-				}
-				err = f.Migrations.Append(m)
+				err = f.appendMigration(m)
 				if err != nil {
 					return err
 				}
 			}
 		} else {
 			// v1 migrate
-			m, err := f.defaultParser(fo.Name())
+			m, err := f.parse(fo.Name())
 			if err != nil {
 				continue // ignore files that we can't parse
 			}
 			m.Raw = fo.Name()
-			ok, err := source.IsEmptyFile(m, f.path)
+			err = f.appendMigration(m)
 			if err != nil {
 				return err
 			}
-			if !ok {
-				continue
-			}
+		}
+	}
+	return nil
+}
+
+// parse tries the repeatable and single file parsers before the default one
+func (f *File) parse(name string) (*source.Migration, error) {
+	if m, err := source.ParseRepeatable(name); err == nil {
+		return m, nil
+	}
+	if m, err := source.ParseSingleFile(name); err == nil {
+		return m, nil
+	}
+	return f.defaultParser(name)
+}
+
+// appendMigration adds m to the migrations unless its content is empty. The
+// down section of a single file migration is added alongside its up section.
+func (f *File) appendMigration(m *source.Migration) error {
+	migrations := []*source.Migration{m}
+	if m.Section != "" {
+		down := *m
+		down.Direction = source.Down
+		down.Section = source.Down
+		migrations = append(migrations, &down)
+	}
+	for _, m := range migrations {
+		ok, err := source.IsEmptyFile(m, f.path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if m.Repeatable {
+			err = f.Migrations.AppendRepeatable(m)
+		} else {
 			err = f.Migrations.Append(m)
-			if err != nil {
-				return err
-			}
+		}
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// open returns a reader for the content of m, which is only the up or down
+// section of the file for single file migrations, read by a source.Section
+func (f *File) open(m *source.Migration) (io.ReadCloser, error) {
+	r, err := os.Open(path.Join(f.path, m.Raw))
+	if err != nil {
+		return nil, err
+	}
+	if m.Section == "" {
+		return r, nil
+	}
+	defer r.Close()
+	data, line, err := source.ReadSection(r, m.Section)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid migration file %s", m.Raw)
+	}
+	return source.NewSection(bytes.NewReader(data), line), nil
+}
+
 func (f *File) First() (version uint64, err error) {
 	if v, ok := f.Migrations.First(); !ok {
 		return 0, &os.PathError{Op: "first", Path: f.path, Err: os.ErrNotExist}
@@ -184,7 +230,7 @@ func (f *File) GetDirections(version uint64) map[source.Direction]bool {
 
 func (f *File) ReadUp(version uint64) (r io.ReadCloser, identifier string, fileName string, err error) {
 	if m, ok := f.Migrations.Up(version); ok {
-		r, err := os.Open(path.Join(f.path, m.Raw))
+		r, err := f.open(m)
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -195,7 +241,7 @@ func (f *File) ReadUp(version uint64) (r io.ReadCloser, identifier string, fileN
 
 func (f *File) ReadMetaUp(version uint64) (r io.ReadCloser, identifier string, fileName string, err error) {
 	if m, ok := f.Migrations.MetaUp(version); ok {
-		r, err := os.Open(path.Join(f.path, m.Raw))
+		r, err := f.open(m)
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -206,7 +252,7 @@ func (f *File) ReadMetaUp(version uint64) (r io.ReadCloser, identifier string, f
 
 func (f *File) ReadDown(version uint64) (r io.ReadCloser, identifier string, fileName string, err error) {
 	if m, ok := f.Migrations.Down(version); ok {
-		r, err := os.Open(path.Join(f.path, m.Raw))
+		r, err := f.open(m)
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -217,7 +263,7 @@ func (f *File) ReadDown(version uint64) (r io.ReadCloser, identifier string, fil
 
 func (f *File) ReadMetaDown(version uint64) (r io.ReadCloser, identifier string, fileName string, err error) {
 	if m, ok := f.Migrations.MetaDown(version); ok {
-		r, err := os.Open(path.Join(f.path, m.Raw))
+		r, err := f.open(m)
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -226,13 +272,40 @@ func (f *File) ReadMetaDown(version uint64) (r io.ReadCloser, identifier string,
 	return nil, "", "", &os.PathError{Op: fmt.Sprintf("read version %v", version), Path: f.path, Err: os.ErrNotExist}
 }
 
+func (f *File) Repeatables() []string {
+	return f.Migrations.RepeatableIndex
+}
+
+func (f *File) ReadRepeatable(name string) (r io.ReadCloser, fileName string, err error) {
+	if m, ok := f.Migrations.Repeatables[name]; ok {
+		r, err := f.open(m)
+		if err != nil {
+			return nil, "", err
+		}
+		return r, m.Raw, nil
+	}
+	return nil, "", &os.PathError{Op: fmt.Sprintf("read repeatable %s", name), Path: f.path, Err: os.ErrNotExist}
+}
+
 func (f *File) ReadName(version uint64) (name string) {
 	return f.Migrations.ReadName(version)
 }
 
+// WriteMetadata writes the metadata files, a nil content removes the file
 func (f *File) WriteMetadata(files map[string][]byte) error {
 	for name, content := range files {
-		err := ioutil.WriteFile(name, content, 0644)
+		if content == nil {
+			err := os.Remove(name)
+			if err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "removing metadata file %s failed", name)
+			}
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(name), os.ModePerm)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory of metadata file %s failed", name)
+		}
+		err = ioutil.WriteFile(name, content, 0644)
 		if err != nil {
 			return errors.Wrapf(err, "creating metadata file %s failed", name)
 		}
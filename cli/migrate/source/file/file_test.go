@@ -1,6 +1,7 @@
 package file
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -177,6 +178,67 @@ func TestOpenWithDuplicateVersion(t *testing.T) {
 	}
 }
 
+func TestSingleFileMigration(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestSingleFileMigration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.Mkdir(filepath.Join(tmpDir, "1_foobar"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, filepath.Join(tmpDir, "1_foobar"), "migration.sql", `-- create foo
+-- migrate:up
+CREATE TABLE foo (id int);
+
+-- migrate:down
+DROP TABLE foo;
+`)
+	mustWriteFile(t, tmpDir, "2_bar.migration.sql", "-- migrate:up\nCREATE TABLE bar (id int);\n-- migrate:down\n")
+
+	logger, _ := test.NewNullLogger()
+	f := &File{}
+	d, err := f.Open("file://"+tmpDir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []struct {
+		version uint64
+		read    func(uint64) (io.ReadCloser, string, string, error)
+		expect  string
+	}{
+		{1, d.ReadUp, "CREATE TABLE foo (id int);\n\n"},
+		{1, d.ReadDown, "DROP TABLE foo;\n"},
+		{2, d.ReadUp, "CREATE TABLE bar (id int);\n"},
+	} {
+		r, identifier, _, err := v.read(v.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != v.expect {
+			t.Errorf("expected %q, got %q for version %d", v.expect, body, v.version)
+		}
+		if identifier == "" {
+			t.Errorf("expected identifier for version %d", v.version)
+		}
+	}
+
+	// the empty down section is not a migration
+	if _, _, _, err := d.ReadDown(2); err == nil {
+		t.Error("expected err not to be nil for empty down section")
+	}
+}
+
 func TestOpenWithInvalidFileURL(t *testing.T) {
 	logger, _ := test.NewNullLogger()
 	f := &File{}
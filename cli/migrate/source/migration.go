@@ -35,6 +35,14 @@ type Migration struct {
 
 	// Check if the file exists in a directory
 	IsDir bool
+
+	// Repeatable migrations have no version and are identified
+	// by their Identifier.
+	Repeatable bool
+
+	// Section is set when Raw is a single file migration, only the
+	// up or down section of the file belongs to this migration.
+	Section Direction
 }
 
 // Migrations wraps Migration and has an internal index
@@ -42,13 +50,37 @@ type Migration struct {
 type Migrations struct {
 	Index      uint64Slice
 	Migrations map[uint64]map[Direction]*Migration
+
+	// Repeatables holds the repeatable migrations by name,
+	// RepeatableIndex holds their names in order.
+	RepeatableIndex []string
+	Repeatables     map[string]*Migration
 }
 
 func NewMigrations() *Migrations {
 	return &Migrations{
-		Index:      make(uint64Slice, 0),
-		Migrations: make(map[uint64]map[Direction]*Migration),
+		Index:           make(uint64Slice, 0),
+		Migrations:      make(map[uint64]map[Direction]*Migration),
+		RepeatableIndex: make([]string, 0),
+		Repeatables:     make(map[string]*Migration),
+	}
+}
+
+// AppendRepeatable adds a repeatable migration
+func (i *Migrations) AppendRepeatable(m *Migration) (err error) {
+	if m == nil {
+		return fmt.Errorf("migration cannot be nill")
+	}
+
+	// reject duplicate names
+	if migration, dup := i.Repeatables[m.Identifier]; dup {
+		return fmt.Errorf("found duplicate repeatable migrations for %s\n- %s\n- %s", m.Identifier, m.Raw, migration.Raw)
 	}
+
+	i.Repeatables[m.Identifier] = m
+	i.RepeatableIndex = append(i.RepeatableIndex, m.Identifier)
+	sort.Strings(i.RepeatableIndex)
+	return nil
 }
 
 func (i *Migrations) Append(m *Migration) (err error) {
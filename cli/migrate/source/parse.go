@@ -1,11 +1,15 @@
 package source
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 
 	yaml "github.com/ghodss/yaml"
 	"github.com/pkg/errors"
@@ -30,6 +34,20 @@ type Parser func(raw string) (*Migration, error)
 var Regex = regexp.MustCompile(`^([0-9]+)_(.*)\.(` + string(Down) + `|` + string(Up) + `)\.(.*)$`)
 var Regexv2 = regexp.MustCompile(`^([0-9]+)_(.*)\.(` + string(Down) + `|` + string(Up) + `)\.(sql)$`)
 
+// RegexRepeatable matches the following pattern of repeatable migrations:
+//  R__name.up.sql
+var RegexRepeatable = regexp.MustCompile(`^R__(.+)\.(` + string(Up) + `)\.(sql)$`)
+
+// RegexSingleFile matches the following pattern of single file migrations:
+//  123_name.migration.sql
+var RegexSingleFile = regexp.MustCompile(`^([0-9]+)_(.*)\.(migration)\.(sql)$`)
+
+// Markers which start the up and down sections of a single file migration.
+const (
+	SectionMarkerUp   = "-- migrate:up"
+	SectionMarkerDown = "-- migrate:down"
+)
+
 // Parse returns Migration for matching Regex pattern.
 func Parse(raw string) (*Migration, error) {
 	var direction Direction
@@ -98,12 +116,143 @@ func Parsev2(raw string) (*Migration, error) {
 	return nil, ErrParse
 }
 
+// ParseRepeatable returns a repeatable Migration for matching RegexRepeatable pattern.
+func ParseRepeatable(raw string) (*Migration, error) {
+	m := RegexRepeatable.FindStringSubmatch(raw)
+	if len(m) == 4 {
+		return &Migration{
+			Identifier: m[1],
+			Direction:  Up,
+			Repeatable: true,
+		}, nil
+	}
+	return nil, ErrParse
+}
+
+// ParseSingleFile returns Migration for matching RegexSingleFile pattern.
+// The returned Migration has the Up section set, the caller is expected to
+// add a copy of it for the Down section.
+func ParseSingleFile(raw string) (*Migration, error) {
+	m := RegexSingleFile.FindStringSubmatch(raw)
+	if len(m) == 5 {
+		versionUint64, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &Migration{
+			Version:    versionUint64,
+			Identifier: m[2],
+			Direction:  Up,
+			Section:    Up,
+		}, nil
+	}
+	return nil, ErrParse
+}
+
+// ReadSection returns the content of the up or down section of a single
+// file migration and the line of the file it starts on, 0 if the file has
+// no such section. Only comments and blank lines are allowed before the
+// up section.
+func ReadSection(r io.Reader, section Direction) ([]byte, int, error) {
+	sections := make(map[Direction]*bytes.Buffer)
+	lines := make(map[Direction]int)
+	var current Direction
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		var marker Direction
+		switch strings.ToLower(strings.TrimSpace(text)) {
+		case SectionMarkerUp:
+			marker = Up
+		case SectionMarkerDown:
+			marker = Down
+		}
+		if marker != "" {
+			if _, ok := sections[marker]; ok {
+				return nil, 0, fmt.Errorf("line %d: duplicate %s section", line, marker)
+			}
+			if marker == Down && current != Up {
+				return nil, 0, fmt.Errorf("line %d: down section found before the up section", line)
+			}
+			sections[marker] = new(bytes.Buffer)
+			lines[marker] = line + 1
+			current = marker
+			continue
+		}
+		if current == "" {
+			trimmed := strings.TrimSpace(text)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return nil, 0, fmt.Errorf("line %d: statement found before the %q marker", line, SectionMarkerUp)
+			}
+			continue
+		}
+		sections[current].WriteString(text)
+		sections[current].WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	if _, ok := sections[Up]; !ok {
+		return nil, 0, fmt.Errorf("%q marker not found", SectionMarkerUp)
+	}
+	if content, ok := sections[section]; ok {
+		return content.Bytes(), lines[section], nil
+	}
+	return []byte{}, 0, nil
+}
+
+// Section is a reader of the content of a section of a single file
+// migration, which knows the line of the file the section starts on
+type Section struct {
+	io.Reader
+	line int
+}
+
+// NewSection returns a Section reading r, which starts on line of its file
+func NewSection(r io.Reader, line int) *Section {
+	return &Section{Reader: r, line: line}
+}
+
+// Line returns the line of the file the section starts on
+func (s *Section) Line() int {
+	return s.line
+}
+
+// Close closes the underlying reader if it is an io.Closer
+func (s *Section) Close() error {
+	if c, ok := s.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Line returns the line of its file r starts on, which is 1 unless r is a
+// Section
+func Line(r io.Reader) int {
+	if s, ok := r.(*Section); ok && s.line > 0 {
+		return s.line
+	}
+	return 1
+}
+
 // Validate file to check for empty sql or yaml content.
 func IsEmptyFile(m *Migration, directory string) (bool, error) {
 	data, err := ioutil.ReadFile(filepath.Join(directory, m.Raw))
 	if err != nil {
 		return false, errors.Wrapf(err, "cannot read file %s", m.Raw)
 	}
+	if m.Section != "" {
+		data, _, err = ReadSection(bytes.NewReader(data), m.Section)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid migration file %s", m.Raw)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			return false, nil
+		}
+	}
 	switch direction := m.Direction; direction {
 	case MetaUp, MetaDown:
 		var t []interface{}
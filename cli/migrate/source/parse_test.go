@@ -1,6 +1,7 @@
 package source
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -117,3 +118,80 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRepeatable(t *testing.T) {
+	tt := []struct {
+		name            string
+		expectErr       error
+		expectMigration *Migration
+	}{
+		{
+			name:      "R__views.up.sql",
+			expectErr: nil,
+			expectMigration: &Migration{
+				Identifier: "views",
+				Direction:  Up,
+				Repeatable: true,
+			},
+		},
+		{
+			name:            "R__views.down.sql",
+			expectErr:       ErrParse,
+			expectMigration: nil,
+		},
+		{
+			name:            "R__views.up.yaml",
+			expectErr:       ErrParse,
+			expectMigration: nil,
+		},
+		{
+			name:            "1_views.up.sql",
+			expectErr:       ErrParse,
+			expectMigration: nil,
+		},
+	}
+
+	for i, v := range tt {
+		f, err := ParseRepeatable(v.name)
+
+		if err != v.expectErr {
+			t.Errorf("expected %v, got %v, in %v", v.expectErr, err, i)
+		}
+
+		if v.expectMigration != nil && *f != *v.expectMigration {
+			t.Errorf("expected %+v, got %+v, in %v", *v.expectMigration, *f, i)
+		}
+	}
+}
+
+func TestReadSection(t *testing.T) {
+	tt := []struct {
+		name      string
+		content   string
+		section   Direction
+		expectErr bool
+		expect    string
+		line      int
+	}{
+		{"up", "-- migrate:up\nup\n-- migrate:down\ndown\n", Up, false, "up\n", 2},
+		{"down", "-- migrate:up\nup\n-- migrate:down\ndown\n", Down, false, "down\n", 4},
+		{"no down", "-- migrate:up\nup\n", Down, false, "", 0},
+		{"comments before up", "-- comment\n\n-- Migrate:Up\nup\n", Up, false, "up\n", 4},
+		{"no up", "-- migrate:down\ndown\n", Down, true, "", 0},
+		{"statement before up", "up\n-- migrate:up\n", Up, true, "", 0},
+		{"duplicate up", "-- migrate:up\n-- migrate:up\n", Up, true, "", 0},
+	}
+
+	for _, v := range tt {
+		data, line, err := ReadSection(strings.NewReader(v.content), v.section)
+		if (err != nil) != v.expectErr {
+			t.Errorf("%s: expected err %v, got %v", v.name, v.expectErr, err)
+		}
+		if err == nil && string(data) != v.expect {
+			t.Errorf("%s: expected %q, got %q", v.name, v.expect, data)
+		}
+		if err == nil && line != v.line {
+			t.Errorf("%s: expected the section to start on line %d, got %d", v.name, v.line, line)
+		}
+	}
+}
@@ -113,6 +113,17 @@ func (s *Stub) ReadMetaDown(version uint64) (r io.ReadCloser, identifier string,
 	return nil, "", "", &os.PathError{Op: fmt.Sprintf("read down yaml version %v", version), Path: s.Url, Err: os.ErrNotExist}
 }
 
+func (s *Stub) Repeatables() []string {
+	return s.Migrations.RepeatableIndex
+}
+
+func (s *Stub) ReadRepeatable(name string) (r io.ReadCloser, fileName string, err error) {
+	if m, ok := s.Migrations.Repeatables[name]; ok {
+		return ioutil.NopCloser(bytes.NewBufferString(m.Identifier)), fmt.Sprintf("R__%s.up.sql.stub", name), nil
+	}
+	return nil, "", &os.PathError{Op: fmt.Sprintf("read repeatable %s", name), Path: s.Url, Err: os.ErrNotExist}
+}
+
 func (f *Stub) ReadName(version uint64) (name string) {
 	return f.Migrations.ReadName(version)
 }
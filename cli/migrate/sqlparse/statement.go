@@ -0,0 +1,170 @@
+// Package sqlparse splits sql migrations into statements.
+package sqlparse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Statement is a single sql statement of a migration
+type Statement struct {
+	// Code is the statement without comments, in upper case with
+	// whitespace collapsed and the content of string literals removed
+	Code string
+	// Text is the statement as written, including the comments and
+	// whitespace preceding it and the terminating semicolon
+	Text string
+	// Line is the line on which the statement starts
+	Line int
+	// Comments holds the comments preceding or inside the statement,
+	// and the ones following it on the same line
+	Comments []string
+}
+
+// Split splits sql into statements, skipping over string
+// literals, quoted identifiers, dollar quoted bodies and comments
+func Split(sql string) []*Statement {
+	var statements []*Statement
+	current := &Statement{}
+	var code strings.Builder
+	line := 1
+	// line on which the last statement ended
+	endLine := 0
+	var last *Statement
+	runes := []rune(sql)
+	// offset at which the current statement starts
+	start := 0
+
+	addComment := func(comment string) {
+		// comments following a statement on the same line belong to it
+		if last != nil && endLine == line && strings.TrimSpace(code.String()) == "" {
+			last.Comments = append(last.Comments, comment)
+			return
+		}
+		current.Comments = append(current.Comments, comment)
+	}
+	writeCode := func(s string) {
+		if current.Line == 0 && strings.TrimSpace(s) != "" {
+			current.Line = line
+		}
+		code.WriteString(s)
+	}
+	finish := func(end int) {
+		current.Code = Normalize(code.String())
+		current.Text = string(runes[start:end])
+		start = end
+		if current.Code != "" {
+			statements = append(statements, current)
+			last = current
+			endLine = line
+		} else if last != nil && len(current.Comments) > 0 {
+			// trailing comments
+			last.Comments = append(last.Comments, current.Comments...)
+		}
+		current = &Statement{}
+		code.Reset()
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			code.WriteRune(c)
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			addComment(string(runes[i+2 : end]))
+			i = end - 1
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			depth := 0
+			start := i
+			for ; i < len(runes); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*' {
+					depth++
+					i++
+				} else if runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+					depth--
+					i++
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			end := i - 1
+			if start+2 < end {
+				addComment(string(runes[start+2 : end]))
+			}
+		case c == '\'' || c == '"':
+			// keep identifiers, drop the content of string literals
+			start := i
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == c {
+					if i+1 < len(runes) && runes[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if c == '"' {
+				end := i + 1
+				if end > len(runes) {
+					end = len(runes)
+				}
+				writeCode(string(runes[start:end]))
+			} else {
+				writeCode("''")
+			}
+		case c == '$':
+			tag, ok := dollarTag(runes[i:])
+			if !ok {
+				writeCode(string(c))
+				continue
+			}
+			tagLen := len([]rune(tag))
+			body := string(runes[i+tagLen:])
+			end := strings.Index(body, tag)
+			if end < 0 {
+				end = len(body)
+			} else {
+				end += len(tag)
+			}
+			line += strings.Count(body[:end], "\n")
+			i += tagLen + len([]rune(body[:end])) - 1
+			writeCode("$$")
+		case c == ';':
+			finish(i + 1)
+		default:
+			writeCode(string(c))
+		}
+	}
+	finish(len(runes))
+	return statements
+}
+
+// dollarTag returns the $tag$ which starts s, if any
+func dollarTag(s []rune) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return string(s[:i+1]), true
+		}
+		if !(unicode.IsLetter(s[i]) || s[i] == '_' || (i > 1 && unicode.IsDigit(s[i]))) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// Normalize collapses whitespace and converts code to upper case
+func Normalize(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), " "))
+}
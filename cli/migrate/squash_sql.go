@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+	"github.com/hasura/graphql-engine/cli/migrate/sqlparse"
+)
+
+var (
+	squashCreateRegex = regexp.MustCompile(`^CREATE (?:(?:GLOBAL |LOCAL )?(?:TEMP |TEMPORARY )|UNLOGGED )?(TABLE|VIEW|MATERIALIZED VIEW|SEQUENCE|TYPE) ([^\s(]+)`)
+	squashIndexRegex  = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (?:CONCURRENTLY )?([^\s(]+) ON `)
+	squashDropRegex   = regexp.MustCompile(`^DROP (TABLE|VIEW|MATERIALIZED VIEW|SEQUENCE|TYPE|INDEX) (?:CONCURRENTLY )?(?:IF EXISTS )?([^\s,]+)(?: CASCADE| RESTRICT)?$`)
+	squashRenameRegex = regexp.MustCompile(` RENAME TO | SET SCHEMA `)
+)
+
+// squashObject is a database object created or dropped by a statement
+type squashObject struct {
+	kind string
+	name string
+}
+
+// SquashSQL squashes the sql migrations from version v up to and including
+// version to into a single up and down migration. If to is 0, the migrations
+// up to the latest one are squashed. It is meant for projects which keep the
+// metadata outside of the migrations (config v2). Statements creating an
+// object which is dropped again later in the range are left out, along with
+// the statements altering the object in between, unless another statement
+// depends on the object.
+// Returns a list of migrations that are squashed: vs
+// the squashed SQL for all UP steps: us
+// the squashed SQL for all down steps: ds
+func (m *Migrate) SquashSQL(v, to uint64) (vs []int64, us []byte, ds []byte, err error) {
+	if err = m.squashRangeExists(v, to); err != nil {
+		return
+	}
+	if err = m.versionUpExists(v); err != nil {
+		return
+	}
+
+	var ups, downs []*sqlparse.Statement
+	for version := v; to == 0 || version <= to; {
+		directions := m.sourceDrv.GetDirections(version)
+		if directions[source.MetaUp] || directions[source.MetaDown] {
+			return nil, nil, nil, fmt.Errorf("version %d has metadata migrations, only sql migrations can be squashed", version)
+		}
+		header := fmt.Sprintf("-- %d_%s", version, m.sourceDrv.ReadName(version))
+		downHeader := header
+		up, _, err := m.ReadSQL(version, source.Up)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, nil, err
+		}
+		down, _, err := m.ReadSQL(version, source.Down)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, nil, err
+		}
+		ups = append(ups, squashStatements(header, up)...)
+		if down == nil {
+			// the combined down migration cannot roll back this version
+			downHeader += "\n-- no down migration"
+		}
+		// down migrations are applied in reverse order
+		downs = append(squashStatements(downHeader, down), downs...)
+		vs = append(vs, int64(version))
+
+		next, err := m.sourceDrv.Next(version)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		version = next
+	}
+	return vs, joinStatements(cancelStatements(ups)), joinStatements(cancelStatements(downs)), nil
+}
+
+// squashStatements splits the sql of a migration into statements, preceded
+// by the header comment naming the migration. Headers are the statements
+// without code.
+func squashStatements(header string, sql []byte) []*sqlparse.Statement {
+	return append([]*sqlparse.Statement{{Text: header}}, sqlparse.Split(string(sql))...)
+}
+
+// joinStatements returns the sql of the statements, one after the other.
+// The header of a migration is left out if none of its statements is left.
+func joinStatements(statements []*sqlparse.Statement) []byte {
+	var buf bytes.Buffer
+	for i, s := range statements {
+		text := strings.TrimSpace(s.Text)
+		if s.Code == "" {
+			empty := i == len(statements)-1 || statements[i+1].Code == ""
+			if empty && !strings.Contains(text, "\n") {
+				continue
+			}
+			if buf.Len() > 0 {
+				// a blank line between the migrations
+				buf.WriteString("\n")
+			}
+		} else if !strings.HasSuffix(text, ";") {
+			text += ";"
+		}
+		buf.WriteString(text)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// cancelStatements returns the statements without the ones creating an object
+// which is dropped again by a later statement, the ones dropping it, and the
+// ones altering it in between. An object is only left out if no other statement
+// in between mentions it, and if it is not created with IF NOT EXISTS or OR
+// REPLACE, as it might exist before. The statements are looked at from the
+// last one, so that objects depending on an object are left out before it.
+func cancelStatements(statements []*sqlparse.Statement) []*sqlparse.Statement {
+	removed := make([]bool, len(statements))
+	for i := len(statements) - 1; i >= 0; i-- {
+		if removed[i] {
+			continue
+		}
+		created, ok := createdObject(statements[i].Code)
+		if !ok {
+			continue
+		}
+		drop := -1
+		for j := i + 1; j < len(statements); j++ {
+			if dropped, ok := droppedObject(statements[j].Code); ok && dropped == created && !removed[j] {
+				drop = j
+				break
+			}
+		}
+		if drop < 0 {
+			continue
+		}
+		owned, ok := ownedStatements(statements, removed, created, i, drop)
+		if !ok {
+			continue
+		}
+		removed[i] = true
+		removed[drop] = true
+		for _, k := range owned {
+			removed[k] = true
+		}
+	}
+
+	result := make([]*sqlparse.Statement, 0, len(statements))
+	for i, s := range statements {
+		if !removed[i] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ownedStatements returns the statements between from and to which mention
+// the object. ok is false if one of them does more than altering the object.
+func ownedStatements(statements []*sqlparse.Statement, removed []bool, object squashObject, from, to int) (owned []int, ok bool) {
+	bare := object.name
+	if i := strings.LastIndex(bare, "."); i >= 0 {
+		bare = bare[i+1:]
+	}
+	mentionRegex := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(bare) + `\b`)
+	// statements altering the object, by its qualified name or by its bare
+	// name if it is in the public schema
+	names := regexp.QuoteMeta(object.name)
+	if strings.HasPrefix(object.name, "PUBLIC.") {
+		names = "(?:" + names + "|" + regexp.QuoteMeta(bare) + ")"
+	}
+	alterRegex := regexp.MustCompile(`^(?:ALTER ` + object.kind + ` (?:IF EXISTS )?(?:ONLY )?|COMMENT ON ` + object.kind + ` |COMMENT ON COLUMN |CREATE (?:UNIQUE )?INDEX .* ON (?:ONLY )?)` + names + `\b`)
+	for k := from + 1; k < to; k++ {
+		code := strings.Replace(statements[k].Code, `"`, "", -1)
+		mention := code
+		if strings.Contains(code, "$$") {
+			// the code has no function bodies
+			mention = strings.Replace(statements[k].Text, `"`, "", -1)
+		}
+		if removed[k] || code == "" || !mentionRegex.MatchString(mention) {
+			continue
+		}
+		if squashRenameRegex.MatchString(code) {
+			return nil, false
+		}
+		if !alterRegex.MatchString(code) {
+			return nil, false
+		}
+		owned = append(owned, k)
+	}
+	return owned, true
+}
+
+// createdObject returns the object created by the statement
+func createdObject(code string) (squashObject, bool) {
+	if match := squashCreateRegex.FindStringSubmatch(code); match != nil && match[2] != "IF" {
+		return squashObject{match[1], objectName(match[2])}, true
+	}
+	if match := squashIndexRegex.FindStringSubmatch(code); match != nil && match[1] != "IF" {
+		return squashObject{"INDEX", objectName(match[1])}, true
+	}
+	return squashObject{}, false
+}
+
+// droppedObject returns the object dropped by the statement
+func droppedObject(code string) (squashObject, bool) {
+	if match := squashDropRegex.FindStringSubmatch(code); match != nil {
+		return squashObject{match[1], objectName(match[2])}, true
+	}
+	return squashObject{}, false
+}
+
+// objectName returns the schema qualified name of an object, the public
+// schema is assumed for unqualified names
+func objectName(name string) string {
+	name = strings.Replace(name, `"`, "", -1)
+	if !strings.Contains(name, ".") {
+		name = "PUBLIC." + name
+	}
+	return name
+}
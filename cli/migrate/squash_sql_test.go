@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+	"github.com/hasura/graphql-engine/cli/migrate/sqlparse"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	_ "github.com/hasura/graphql-engine/cli/migrate/source/file"
+)
+
+func TestCancelStatements(t *testing.T) {
+	tt := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			"created and dropped",
+			`CREATE TABLE users (id int); ALTER TABLE users ADD COLUMN name text; CREATE INDEX users_name ON users (name); DROP TABLE users;`,
+			"",
+		},
+		{
+			"quoted and qualified",
+			`CREATE TABLE "public"."users" (id int); COMMENT ON TABLE users IS 'users'; DROP TABLE public.users;`,
+			"",
+		},
+		{
+			"unrelated statements are kept",
+			`CREATE TABLE users (id int); CREATE TABLE posts (id int); DROP TABLE users;`,
+			"CREATE TABLE posts (id int);\n",
+		},
+		{
+			"dependent object",
+			`CREATE TABLE users (id int); CREATE VIEW active_users AS SELECT * FROM users; DROP TABLE users CASCADE;`,
+			"CREATE TABLE users (id int);\nCREATE VIEW active_users AS SELECT * FROM users;\nDROP TABLE users CASCADE;\n",
+		},
+		{
+			"dependent object dropped before",
+			`CREATE TABLE users (id int); CREATE VIEW active_users AS SELECT * FROM users; DROP VIEW active_users; DROP TABLE users;`,
+			"",
+		},
+		{
+			"index dropped before the table",
+			`CREATE TABLE users (id int); CREATE INDEX users_id ON users (id); DROP INDEX users_id; DROP TABLE users;`,
+			"",
+		},
+		{
+			"function body",
+			`CREATE TABLE users (id int); CREATE FUNCTION count_users() RETURNS bigint AS $$ SELECT count(*) FROM users $$ LANGUAGE sql; DROP TABLE users;`,
+			"CREATE TABLE users (id int);\nCREATE FUNCTION count_users() RETURNS bigint AS $$ SELECT count(*) FROM users $$ LANGUAGE sql;\nDROP TABLE users;\n",
+		},
+		{
+			"data in between",
+			`CREATE TABLE users (id int); INSERT INTO users VALUES (1); DROP TABLE users;`,
+			"CREATE TABLE users (id int);\nINSERT INTO users VALUES (1);\nDROP TABLE users;\n",
+		},
+		{
+			"might exist before",
+			`CREATE TABLE IF NOT EXISTS users (id int); DROP TABLE users;`,
+			"CREATE TABLE IF NOT EXISTS users (id int);\nDROP TABLE users;\n",
+		},
+		{
+			"other schema",
+			`CREATE TABLE users (id int); DROP TABLE auth.users;`,
+			"CREATE TABLE users (id int);\nDROP TABLE auth.users;\n",
+		},
+	}
+
+	for _, v := range tt {
+		got := string(joinStatements(cancelStatements(sqlparse.Split(v.sql))))
+		if got != v.expected {
+			t.Errorf("%s: expected %q, got %q", v.name, v.expected, got)
+		}
+	}
+}
+
+func TestSquashSQL(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"1_create_users/up.sql":     "CREATE TABLE users (id int);",
+		"1_create_users/down.sql":   "DROP TABLE users;",
+		"2_create_posts/up.sql":     "-- posts of users\nCREATE TABLE posts (id int);",
+		"2_create_posts/down.sql":   "DROP TABLE posts;",
+		"3_drop_users/up.sql":       "DROP TABLE users;",
+		"4_create_authors/up.sql":   "CREATE TABLE authors (id int);",
+		"4_create_authors/down.sql": "DROP TABLE authors;",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger, _ := test.NewNullLogger()
+	drv, err := source.Open("file://"+tmpDir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := drv.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	m := &Migrate{sourceDrv: drv}
+
+	versions, up, down, err := m.SquashSQL(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[2] != 3 {
+		t.Fatalf("expected versions 1 to 3, got %v", versions)
+	}
+	expectedUp := "-- 2_create_posts\n-- posts of users\nCREATE TABLE posts (id int);\n"
+	if string(up) != expectedUp {
+		t.Errorf("expected up %q, got %q", expectedUp, up)
+	}
+	// the users table is not created again as 3_drop_users has no down migration
+	expectedDown := "-- 3_drop_users\n-- no down migration\n\n-- 2_create_posts\nDROP TABLE posts;\n\n-- 1_create_users\nDROP TABLE users;\n"
+	if string(down) != expectedDown {
+		t.Errorf("expected down %q, got %q", expectedDown, down)
+	}
+
+	if _, _, _, err := m.SquashSQL(3, 1); err == nil {
+		t.Error("expected error for squashing to a version before the first one")
+	}
+}
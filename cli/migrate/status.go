@@ -16,17 +16,35 @@ type MigrationStatus struct {
 
 	// Check if the migration is present on the local.
 	IsPresent bool `json:"source_status"`
+
+	// Check if the migration is marked as dirty on the cluster,
+	// i.e. it failed and requires user interaction.
+	IsDirty bool `json:"dirty"`
+
+	// Check if the local up migration files were modified after
+	// the migration was applied on the cluster.
+	IsModified bool `json:"modified"`
+
+	// Check if the migration is not applied on the cluster
+	// but is older than the last applied migration.
+	IsOutOfOrder bool `json:"out_of_order"`
 }
 
 type Status struct {
 	Index      uint64Slice                 `json:"migrations"`
 	Migrations map[uint64]*MigrationStatus `json:"status"`
+
+	// Repeatables holds the status of the repeatable migrations,
+	// sorted by name
+	Repeatables []*RepeatableStatus `json:"repeatables"`
 }
 
 func NewStatus() *Status {
 	return &Status{
 		Index:      make(uint64Slice, 0),
 		Migrations: make(map[uint64]*MigrationStatus),
+
+		Repeatables: make([]*RepeatableStatus, 0),
 	}
 }
 
@@ -40,6 +58,8 @@ func (i *Status) Append(m *MigrationStatus) (ok bool) {
 	} else {
 		// If the Version already exists
 		i.Migrations[m.Version].IsApplied = m.IsApplied
+		i.Migrations[m.Version].IsDirty = m.IsDirty
+		i.Migrations[m.Version].IsModified = m.IsModified
 	}
 
 	i.buildIndex()
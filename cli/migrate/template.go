@@ -0,0 +1,81 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/hasura/graphql-engine/cli/migrate/source"
+	"github.com/pkg/errors"
+)
+
+// ErrUndefinedEnv is returned when a migration template refers to
+// an environment variable which is not set
+type ErrUndefinedEnv struct {
+	Name string
+}
+
+func (e ErrUndefinedEnv) Error() string {
+	return fmt.Sprintf("environment variable %s is not defined", e.Name)
+}
+
+var templateFuncs = template.FuncMap{
+	"env": func(name string) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", ErrUndefinedEnv{name}
+		}
+		return value, nil
+	},
+}
+
+// render executes the sql migration in r as a template, the environment
+// variables are available through the env function, e.g. {{ env "APP_ROLE" }}
+func render(r io.Reader, fileName string) (io.Reader, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.New(fileName).Option("missingkey=error").Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid template in %s", fileName)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, nil); err != nil {
+		return nil, errors.Wrapf(err, "cannot render %s", fileName)
+	}
+	return &buf, nil
+}
+
+// EscapeTemplate escapes the template delimiters in sql, so that it renders
+// to itself, e.g. a schema dump which is saved as a migration
+func EscapeTemplate(sql string) string {
+	return strings.Replace(sql, "{{", `{{"{{"}}`, -1)
+}
+
+// sqlBody returns the body of migr which is sent to the database, rendered
+// as a template when templating is enabled. The files are read as they are
+// everywhere else, e.g. for checksums and squash, so that they do not
+// depend on the environment. The body of a section of a single file
+// migration is a source.Section, so that errors are located in the file.
+func (m *Migrate) sqlBody(migr *Migration) (io.Reader, error) {
+	if migr.BufferedBody == nil {
+		return nil, nil
+	}
+	body := migr.BufferedBody
+	if m.Templating && migr.FileType == "sql" {
+		rendered, err := render(migr.BufferedBody, migr.FileName)
+		if err != nil {
+			return nil, err
+		}
+		body = rendered
+	}
+	if migr.Line > 1 {
+		return source.NewSection(body, migr.Line), nil
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSQLBody(t *testing.T) {
+	os.Setenv("TEST_MIGRATION_ROLE", "app")
+	defer os.Unsetenv("TEST_MIGRATION_ROLE")
+	os.Unsetenv("TEST_MIGRATION_UNDEFINED")
+
+	up := `GRANT SELECT ON foo TO {{ env "TEST_MIGRATION_ROLE" }};`
+	tests := []struct {
+		name       string
+		templating bool
+		body       string
+		expected   string
+		wantErr    bool
+	}{
+		{"rendered", true, up, "GRANT SELECT ON foo TO app;", false},
+		{"templating disabled", false, up, up, false},
+		{"undefined variable", true, `REVOKE SELECT ON foo FROM {{ env "TEST_MIGRATION_UNDEFINED" }};`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Migrate{Templating: tt.templating}
+			migr := &Migration{FileType: "sql", FileName: "1_foobar.up.sql", BufferedBody: strings.NewReader(tt.body)}
+			r, err := m.sqlBody(migr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected err not to be nil for undefined environment variable")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestEscapeTemplate(t *testing.T) {
+	dump := `CREATE FUNCTION public.braces() RETURNS text AS $$ SELECT '{{ env "HOME" }} {{'::text $$ LANGUAGE sql;`
+	r, err := render(strings.NewReader(EscapeTemplate(dump)), "1_init.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != dump {
+		t.Errorf("expected %q, got %q", dump, body)
+	}
+}
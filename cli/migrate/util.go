@@ -5,6 +5,7 @@ import (
 	nurl "net/url"
 	"runtime"
 	"strings"
+	"time"
 
 	crontriggers "github.com/hasura/graphql-engine/cli/metadata/cron_triggers"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/hasura/graphql-engine/cli/metadata/actions"
 	"github.com/hasura/graphql-engine/cli/metadata/allowlist"
 	"github.com/hasura/graphql-engine/cli/metadata/functions"
+	"github.com/hasura/graphql-engine/cli/metadata/overlay"
 	"github.com/hasura/graphql-engine/cli/metadata/querycollections"
 	"github.com/hasura/graphql-engine/cli/metadata/remoteschemas"
 	"github.com/hasura/graphql-engine/cli/metadata/tables"
@@ -19,6 +21,8 @@ import (
 	"github.com/hasura/graphql-engine/cli/metadata/version"
 
 	"github.com/hasura/graphql-engine/cli"
+	"github.com/hasura/graphql-engine/cli/migrate/database/hasuradb"
+	"github.com/hasura/graphql-engine/cli/util"
 	"github.com/pkg/errors"
 )
 
@@ -125,6 +129,22 @@ func NewMigrate(ec *cli.ExecutionContext, isCmd bool) (*Migrate, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create migrate instance")
 	}
+	// the driver stops waiting for the lock after its own timeout, give it
+	// the chance to report the holder of the lock before timing out
+	lockTimeout := hasuradb.DefaultLockTimeout
+	if ec.Config.MigrationsLockTimeout != "" {
+		lockTimeout, err = time.ParseDuration(ec.Config.MigrationsLockTimeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid migrations lock timeout")
+		}
+	}
+	t.LockTimeout = lockTimeout + DefaultLockTimeout
+	t.ChunkSize, err = util.ParseByteSize(ec.Config.MigrationsChunkSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid migrations chunk size")
+	}
+	t.ChunkCount = ec.Config.MigrationsChunkCount
+	t.Templating = ec.Config.MigrationsTemplating
 	// Set Plugins
 	SetMetadataPluginsWithDir(ec, t)
 	if ec.Config.Version == cli.V2 {
@@ -152,11 +172,35 @@ func GetDataPath(ec *cli.ExecutionContext) *nurl.URL {
 	for k, v := range ec.HGEHeaders {
 		q.Add("headers", fmt.Sprintf("%s:%s", k, v))
 	}
+	if ec.Config.MigrationsLockTimeout != "" {
+		q.Set("lock_timeout", ec.Config.MigrationsLockTimeout)
+	}
+	if ec.Config.MigrationsSchema != "" {
+		q.Set("schema", ec.Config.MigrationsSchema)
+	}
+	if ec.Config.MigrationsTable != "" {
+		q.Set("migrations_table", ec.Config.MigrationsTable)
+	}
+	if ec.Config.SettingsTable != "" {
+		q.Set("settings_table", ec.Config.SettingsTable)
+	}
+	if ec.Config.MigrationsActor != "" {
+		q.Set("actor", ec.Config.MigrationsActor)
+	}
+	if ec.Version != nil {
+		q.Set("cli_version", ec.Version.GetCLIVersion())
+	}
 	host.RawQuery = q.Encode()
 	return host
 }
 
 func SetMetadataPluginsWithDir(ec *cli.ExecutionContext, drv *Migrate, dir ...string) {
+	drv.SetMetadataPlugins(GetMetadataPluginsWithDir(ec, dir...))
+}
+
+// GetMetadataPluginsWithDir returns the plugins which build the metadata from
+// the metadata directory, or from the metadata file of a config v1 project
+func GetMetadataPluginsWithDir(ec *cli.ExecutionContext, dir ...string) types.MetadataPlugins {
 	var metadataDir string
 	if len(dir) == 0 {
 		metadataDir = ec.MetadataDir
@@ -173,10 +217,14 @@ func SetMetadataPluginsWithDir(ec *cli.ExecutionContext, drv *Migrate, dir ...st
 		plugins = append(plugins, remoteschemas.New(ec, metadataDir))
 		plugins = append(plugins, actions.New(ec, metadataDir))
 		plugins = append(plugins, crontriggers.New(ec, metadataDir))
+		if ec.MetadataEnvironment != "" && metadataDir == ec.MetadataDir {
+			// overlays are applied last, to the metadata of all the plugins
+			plugins = append(plugins, overlay.New(ec, metadataDir))
+		}
 	} else {
 		plugins = append(plugins, metadata.New(ec, ec.MigrationDir))
 	}
-	drv.SetMetadataPlugins(plugins)
+	return plugins
 }
 
 func GetFilePath(dir string) *nurl.URL {
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var byteSizeRegex = regexp.MustCompile(`^([0-9]+)\s*([KMG]?B?)$`)
+
+var byteSizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+}
+
+// ParseByteSize parses a size in bytes with an optional unit, e.g. "512KB"
+// or "1MB". Units are powers of 1024. An empty size is 0.
+func ParseByteSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if size == "" {
+		return 0, nil
+	}
+	match := byteSizeRegex.FindStringSubmatch(size)
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q, should be a number of bytes with an optional unit (KB, MB, GB)", size)
+	}
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %v", size, err)
+	}
+	return n * byteSizeUnits[match[2]], nil
+}